@@ -1,40 +1,107 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	"transaction-technical-test/internal/config"
+	"transaction-technical-test/internal/domain"
 	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/middleware"
 	"transaction-technical-test/internal/repository"
 	"transaction-technical-test/internal/router"
 	"transaction-technical-test/internal/service"
 )
 
 func main() {
-	// Init database
-	db := config.InitDB()
-
 	// Init logger
 	logger := config.InitLogger()
 	defer logger.Sync()
 
+	// Init database
+	db := config.InitDB(logger)
+
 	// Repository
+	transactor := repository.NewTransactor(db)
 	transactionRepo := repository.NewTransactionRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
 
 	// Service
-	transactionService := service.NewTransactionService(transactionRepo)
-	dashboardService := service.NewDashboardService(transactionRepo)
+	feed := service.NewBroadcaster(config.StreamBufferSize, logger)
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	walletService := service.NewWalletService(walletRepo)
+	transactionService := service.NewTransactionService(transactor, transactionRepo, ledgerService, walletService, feed)
+	location := config.BusinessLocation()
+	dashboardService := service.NewDashboardService(transactionRepo, location)
+	refundService := service.NewRefundService(transactor, transactionRepo, refundRepo, ledgerService, walletService, feed)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, config.IdempotencyTTL())
+	authorizationService := service.NewAuthorizationService(transactor, transactionRepo, ledgerService, walletService, feed, config.AuthorizationTTL())
+
+	// Background job
+	go purgeIdempotencyKeys(idempotencyService, config.IdempotencyPurgeInterval(), logger)
+	go voidExpiredAuthorizations(authorizationService, config.AuthorizationExpiryInterval(), logger)
 
 	// Handler
-	transactionHandler := handler.NewTransactionHandler(transactionService, logger)
+	transactionHandler := handler.NewTransactionHandler(transactionService, idempotencyService, location, logger)
+	refundHandler := handler.NewRefundHandler(refundService, logger)
+	authorizationHandler := handler.NewAuthorizationHandler(authorizationService, logger)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService, logger)
+	walletHandler := handler.NewWalletHandler(walletService, logger)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService, logger)
+	streamHandler := handler.NewStreamHandler(feed, config.StreamHeartbeatInterval(), logger)
 
 	// Router
 	r := gin.Default()
-	router.RegisterRoutes(r, transactionHandler, dashboardHandler)
+	// RequestID pertama supaya semua log setelahnya membawa request_id
+	r.Use(middleware.RequestID(logger))
+	// Errors membungkus semua middleware dan handler lain supaya error-nya
+	// ditulis sebagai problem+json
+	r.Use(middleware.Errors(logger))
+	// live feed SSE terbuka lama, tidak boleh diputus query timeout
+	r.Use(middleware.Timeout(config.QueryTimeout(), "/api/dashboard/stream"))
+	r.Use(middleware.Audit(config.AdminTokens()))
+	router.RegisterRoutes(r, transactionHandler, refundHandler, authorizationHandler, ledgerHandler, walletHandler, dashboardHandler, streamHandler)
 
 	log.Println("server running on :8080")
 	log.Fatal(r.Run(":8080"))
 }
+
+func purgeIdempotencyKeys(s *service.IdempotencyService, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := s.PurgeExpired(context.Background())
+		if err != nil {
+			logger.Error("failed to purge idempotency keys", zap.Error(err))
+			continue
+		}
+		logger.Info("idempotency keys purged", zap.Int64("count", purged))
+	}
+}
+
+func voidExpiredAuthorizations(s *service.AuthorizationService, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := domain.WithAuditInfo(context.Background(), domain.AuditInfo{
+		Actor: "system:authorization-expiry",
+	})
+
+	for range ticker.C {
+		voided, err := s.VoidExpired(ctx)
+		if err != nil {
+			logger.Error("failed to void expired authorizations", zap.Error(err))
+		}
+		if voided > 0 {
+			logger.Info("expired authorizations voided", zap.Int("count", voided))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/config"
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/repository"
+	"transaction-technical-test/internal/service"
+)
+
+// purge menghapus permanen transaksi yang sudah di-soft delete lebih lama
+// dari retention. Dijalankan manual atau lewat cron:
+//
+//	go run cmd/purge/main.go -retention 8760h
+func main() {
+	retention := flag.Duration("retention", config.TransactionRetention(), "hapus transaksi yang di-soft delete lebih lama dari ini")
+	flag.Parse()
+
+	if *retention <= 0 {
+		log.Fatalf("retention must be positive, got %s", *retention)
+	}
+
+	// Init logger
+	logger := config.InitLogger()
+	defer logger.Sync()
+
+	// Init database
+	db := config.InitDB(logger)
+
+	// Repository
+	transactor := repository.NewTransactor(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+
+	// Service
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	walletService := service.NewWalletService(walletRepo)
+	// purge tidak mengubah status transaksi, feed tidak punya subscriber
+	transactionService := service.NewTransactionService(transactor, transactionRepo, ledgerService, walletService, service.NewBroadcaster(0, logger))
+
+	ctx := domain.WithAuditInfo(context.Background(), domain.AuditInfo{
+		Actor: "system:purge",
+	})
+
+	purged, err := transactionService.PurgeDeleted(ctx, *retention)
+	if err != nil {
+		logger.Fatal("failed to purge deleted transactions", zap.Error(err))
+	}
+
+	logger.Info("deleted transactions purged",
+		zap.Int64("count", purged),
+		zap.Duration("retention", *retention),
+	)
+}
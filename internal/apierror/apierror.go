@@ -0,0 +1,152 @@
+// Package apierror memetakan error aplikasi ke status HTTP dan code yang
+// stabil, sehingga client bisa bercabang berdasarkan code tanpa membaca
+// pesan error.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// Code untuk error yang berasal dari request, bukan dari domain
+const (
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeInvalidHeader        = "invalid_header"
+	CodeInvalidBody          = "invalid_body"
+	CodeValidationFailed     = "validation_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeTimeout              = "timeout"
+	CodeClientClosedRequest  = "client_closed_request"
+	CodeInternal             = "internal_error"
+)
+
+// StatusClientClosedRequest adalah status non-standar (nginx) untuk request
+// yang dibatalkan client sebelum response dikirim.
+const StatusClientClosedRequest = 499
+
+// Error adalah error dengan status HTTP dan code stabil. Detail ditampilkan
+// ke client apa adanya, jadi tidak boleh berisi error internal.
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	// Fields berisi detail per field untuk CodeValidationFailed
+	Fields []FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FieldError adalah kesalahan validasi satu field request. Allowed berisi
+// nilai yang diizinkan jika field berupa enum.
+type FieldError struct {
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// New membuat Error dari request yang tidak valid
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// BadRequest adalah New dengan status 400
+func BadRequest(code, detail string) *Error {
+	return New(http.StatusBadRequest, code, detail)
+}
+
+// Validation adalah 400 CodeValidationFailed dengan detail per field
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "request validation failed",
+		Fields: fields,
+	}
+}
+
+// WithStatus mengganti status hasil pemetaan err tanpa mengubah code-nya,
+// untuk endpoint yang semantiknya berbeda (misal 412 untuk If-Match)
+func WithStatus(status int, err error) *Error {
+	e := *From(err)
+	e.Status = status
+	return &e
+}
+
+// mapping adalah status dan code untuk error domain. Pesan error domain
+// aman ditampilkan ke client.
+var mapping = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+	{domain.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{domain.ErrCursorSortMismatch, http.StatusBadRequest, "cursor_sort_mismatch"},
+	{domain.ErrInvalidSort, http.StatusBadRequest, "invalid_sort"},
+	{domain.ErrInvalidStatus, http.StatusBadRequest, "invalid_status"},
+	{domain.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+	{domain.ErrInvalidCurrency, http.StatusBadRequest, "invalid_currency"},
+	{domain.ErrCurrencyMismatch, http.StatusBadRequest, "currency_mismatch"},
+	{domain.ErrInvalidInterval, http.StatusBadRequest, "invalid_interval"},
+	{domain.ErrInvalidTimeRange, http.StatusBadRequest, "invalid_time_range"},
+	{domain.ErrInvalidAmountRange, http.StatusBadRequest, "invalid_amount_range"},
+	{domain.ErrTooManyBuckets, http.StatusBadRequest, "too_many_buckets"},
+	{domain.ErrInvalidWindow, http.StatusBadRequest, "invalid_window"},
+	{domain.ErrInvalidRankBy, http.StatusBadRequest, "invalid_rank_by"},
+
+	{domain.ErrAdminRequired, http.StatusForbidden, "admin_required"},
+
+	{domain.ErrNotDeleted, http.StatusConflict, "not_deleted"},
+	{domain.ErrConcurrentModification, http.StatusConflict, "concurrent_modification"},
+	{domain.ErrInvalidTransition, http.StatusConflict, "invalid_transition"},
+	{domain.ErrNotRefundable, http.StatusConflict, "not_refundable"},
+	{domain.ErrAuthorizationExpired, http.StatusConflict, "authorization_expired"},
+	{domain.ErrAuthorizationActive, http.StatusConflict, "authorization_active"},
+	{domain.ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency_key_in_progress"},
+
+	{domain.ErrRefundExceedsAmount, http.StatusUnprocessableEntity, "refund_exceeds_amount"},
+	{domain.ErrCaptureExceedsAuthorized, http.StatusUnprocessableEntity, "capture_exceeds_authorized"},
+	{domain.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+	{domain.ErrIdempotencyKeyMismatch, http.StatusUnprocessableEntity, "idempotency_key_mismatch"},
+
+	{domain.ErrLedgerImbalanced, http.StatusInternalServerError, "ledger_imbalanced"},
+}
+
+// From memetakan err: *Error di dalam chain dipakai apa adanya, error domain
+// dan context memakai tabel, sisanya 500 CodeInternal tanpa Detail.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	for _, m := range mapping {
+		if errors.Is(err, m.err) {
+			return &Error{Status: m.status, Code: m.code, Detail: m.err.Error(), Err: err}
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Detail: "request timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Status: StatusClientClosedRequest, Code: CodeClientClosedRequest, Detail: "request was canceled", Err: err}
+	default:
+		return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Err: err}
+	}
+}
@@ -0,0 +1,94 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "Domain Error",
+			err:        domain.ErrTransactionNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "transaction_not_found",
+			wantDetail: domain.ErrTransactionNotFound.Error(),
+		},
+		{
+			// detail memakai pesan sentinel, bukan pesan wrap yang bisa berisi detail internal
+			name:       "Wrapped Domain Error",
+			err:        fmt.Errorf("refund tx 7 in db shard-2: %w", domain.ErrRefundExceedsAmount),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "refund_exceeds_amount",
+			wantDetail: domain.ErrRefundExceedsAmount.Error(),
+		},
+		{
+			name:       "Deadline",
+			err:        fmt.Errorf("query: %w", context.DeadlineExceeded),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   CodeTimeout,
+			wantDetail: "request timed out",
+		},
+		{
+			name:       "Canceled",
+			err:        context.Canceled,
+			wantStatus: StatusClientClosedRequest,
+			wantCode:   CodeClientClosedRequest,
+			wantDetail: "request was canceled",
+		},
+		{
+			name:       "Unknown",
+			err:        errors.New("Error 1045: Access denied for user 'app'@'10.0.0.3'"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+		{
+			name:       "Api Error",
+			err:        BadRequest(CodeInvalidParameter, "invalid id"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeInvalidParameter,
+			wantDetail: "invalid id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, tt.wantDetail, got.Detail)
+		})
+	}
+}
+
+func TestWithStatus(t *testing.T) {
+	got := WithStatus(http.StatusPreconditionFailed, domain.ErrConcurrentModification)
+
+	assert.Equal(t, http.StatusPreconditionFailed, got.Status)
+	assert.Equal(t, "concurrent_modification", got.Code)
+	assert.ErrorIs(t, got, domain.ErrConcurrentModification)
+	// tabel tidak ikut berubah
+	assert.Equal(t, http.StatusConflict, From(domain.ErrConcurrentModification).Status)
+}
+
+func TestMapping_UniqueCodes(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, m := range mapping {
+		assert.False(t, seen[m.code], "duplicate code %s", m.code)
+		seen[m.code] = true
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "strings"
+
+// AdminTokens adalah daftar token rahasia (pisah koma) yang boleh melihat
+// dan memulihkan transaksi terhapus. Lebih dari satu token dipakai saat
+// rotasi. Default kosong: tidak ada admin.
+func AdminTokens() []string {
+	var tokens []string
+	for _, token := range strings.Split(getEnv("ADMIN_TOKENS", ""), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAdminTokens(t *testing.T) {
+	t.Setenv("ADMIN_TOKENS", "")
+	if got := AdminTokens(); len(got) != 0 {
+		t.Fatalf("expected no admin tokens by default, got %v", got)
+	}
+
+	t.Setenv("ADMIN_TOKENS", " old-secret, ,new-secret ")
+	if got := AdminTokens(); !slices.Equal(got, []string{"old-secret", "new-secret"}) {
+		t.Fatalf("unexpected admin tokens: %v", got)
+	}
+}
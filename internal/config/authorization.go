@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+// AuthorizationTTL adalah lama dana ditahan sebelum authorization
+// otomatis di-void, default 7 hari
+func AuthorizationTTL() time.Duration {
+	return getEnvDuration("AUTHORIZATION_TTL", 7*24*time.Hour)
+}
+
+// AuthorizationExpiryInterval adalah jeda worker yang me-void authorization expired
+func AuthorizationExpiryInterval() time.Duration {
+	return getEnvDuration("AUTHORIZATION_EXPIRY_INTERVAL", time.Minute)
+}
@@ -4,14 +4,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
 	"transaction-technical-test/internal/repository"
 )
 
-func InitDB() *gorm.DB {
+// InitDB membuka koneksi MySQL dengan log query lewat logger; query gagal
+// dan slow query dicatat dengan request_id dari context
+func InitDB(logger *zap.Logger) *gorm.DB {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		getEnv("DB_USER", "root"),
@@ -21,23 +25,63 @@ func InitDB() *gorm.DB {
 		getEnv("DB_NAME", "transactions_db"),
 	)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: repository.NewGormLogger(logger, SlowQueryThreshold()),
+	})
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 
 	// Auto migrate table
-	if err := db.AutoMigrate(&repository.TransactionModel{}); err != nil {
+	if err := db.AutoMigrate(
+		&repository.TransactionModel{},
+		&repository.IdempotencyKeyModel{},
+		&repository.RefundModel{},
+		&repository.LedgerAccountModel{},
+		&repository.JournalEntryModel{},
+		&repository.PostingModel{},
+		&repository.WalletModel{},
+		&repository.TransactionEventModel{},
+	); err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
+	// Pindahkan kolom amount float lama ke minor unit
+	if err := repository.MigrateLegacyAmount(db); err != nil {
+		log.Fatalf("failed to migrate legacy amount column: %v", err)
+	}
+
 	log.Println("database connected & migrated")
 	return db
 }
 
+// SlowQueryThreshold adalah batas query dicatat sebagai slow query, default 200ms
+func SlowQueryThreshold() time.Duration {
+	return getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond)
+}
+
+// QueryTimeout adalah batas waktu per request untuk query database, default 10 detik
+func QueryTimeout() time.Duration {
+	return getEnvDuration("QUERY_TIMEOUT", 10*time.Second)
+}
+
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
 	}
 	return defaultVal
 }
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("invalid duration for %s: %q, using default %s", key, val, defaultVal)
+		return defaultVal
+	}
+	return d
+}
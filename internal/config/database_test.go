@@ -4,6 +4,9 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 func TestGetEnv_Default(t *testing.T) {
@@ -30,7 +33,7 @@ func TestInitDB_Fail(t *testing.T) {
 		os.Setenv("DB_PASSWORD", "invalid")
 		os.Setenv("DB_NAME", "invalid")
 
-		InitDB()
+		InitDB(zap.NewNop())
 		return
 	}
 
@@ -43,3 +46,21 @@ func TestInitDB_Fail(t *testing.T) {
 		t.Fatalf("expected InitDB to exit with error")
 	}
 }
+
+func TestGetEnvDuration(t *testing.T) {
+	os.Setenv("TEST_DURATION", "90s")
+	defer os.Unsetenv("TEST_DURATION")
+
+	if d := getEnvDuration("TEST_DURATION", time.Minute); d != 90*time.Second {
+		t.Fatalf("expected 90s, got %s", d)
+	}
+
+	if d := getEnvDuration("ENV_NOT_EXIST", time.Minute); d != time.Minute {
+		t.Fatalf("expected default, got %s", d)
+	}
+
+	os.Setenv("TEST_DURATION", "abc")
+	if d := getEnvDuration("TEST_DURATION", time.Minute); d != time.Minute {
+		t.Fatalf("expected default for invalid value, got %s", d)
+	}
+}
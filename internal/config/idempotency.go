@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// IdempotencyTTL adalah lama Idempotency-Key disimpan, default 24 jam
+func IdempotencyTTL() time.Duration {
+	return getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+}
+
+// IdempotencyPurgeInterval adalah jeda pembersihan key yang sudah expired
+func IdempotencyPurgeInterval() time.Duration {
+	return getEnvDuration("IDEMPOTENCY_PURGE_INTERVAL", time.Hour)
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+// TransactionRetention adalah lama transaksi yang di-soft delete disimpan
+// sebelum boleh di-purge, default 365 hari
+func TransactionRetention() time.Duration {
+	return getEnvDuration("TRANSACTION_RETENTION", 365*24*time.Hour)
+}
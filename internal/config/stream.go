@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// StreamBufferSize adalah jumlah event live feed yang ditahan per client
+// sebelum event berikutnya dibuang
+const StreamBufferSize = 64
+
+// StreamHeartbeatInterval adalah jeda komentar keepalive di live feed SSE
+// supaya proxy tidak memutus koneksi yang sedang sepi
+func StreamHeartbeatInterval() time.Duration {
+	return getEnvDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second)
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	// Embed database zona waktu supaya LoadLocation tetap jalan di image
+	// tanpa tzdata (misal distroless / scratch)
+	_ "time/tzdata"
+)
+
+// defaultBusinessTimezone adalah zona waktu operator
+const defaultBusinessTimezone = "Asia/Jakarta"
+
+// BusinessLocation adalah zona waktu yang menentukan batas hari bisnis,
+// misal "hari ini" di dashboard. Diatur lewat BUSINESS_TIMEZONE.
+func BusinessLocation() *time.Location {
+	name := getEnv("BUSINESS_TIMEZONE", defaultBusinessTimezone)
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("invalid BUSINESS_TIMEZONE %q, using default %s", name, defaultBusinessTimezone)
+		loc, _ = time.LoadLocation(defaultBusinessTimezone)
+	}
+	return loc
+}
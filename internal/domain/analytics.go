@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Interval adalah ukuran bucket untuk analytics time-series
+type Interval string
+
+const (
+	IntervalHour  Interval = "hour"
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// MaxBuckets membatasi jumlah bucket per query supaya jumlah parameter SQL
+// tetap di bawah batas database (satu parameter per bucket)
+const MaxBuckets = 1000
+
+// ParseInterval memvalidasi interval dari query string
+func ParseInterval(s string) (Interval, error) {
+	switch i := Interval(s); i {
+	case IntervalHour, IntervalDay, IntervalWeek, IntervalMonth:
+		return i, nil
+	default:
+		return "", ErrInvalidInterval
+	}
+}
+
+// Buckets mengembalikan batas bucket yang menutupi [from, to). Batas
+// berikutnya mengikuti awal interval di zona waktu loc (minggu dimulai
+// Senin), tapi batas pertama tetap from supaya bucket pertama tidak
+// melaporkan window yang lebih lebar dari query. Hasilnya berisi n+1 batas
+// untuk n bucket.
+func (i Interval) Buckets(from, to time.Time, loc *time.Location) ([]time.Time, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	start := from.In(loc)
+	boundaries := []time.Time{start}
+	for cur := i.truncate(start); cur.Before(to); {
+		if len(boundaries) > MaxBuckets {
+			return nil, ErrTooManyBuckets
+		}
+		cur = i.next(cur)
+		boundaries = append(boundaries, cur)
+	}
+
+	return boundaries, nil
+}
+
+func (i Interval) truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch i {
+	case IntervalHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case IntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next memakai AddDate untuk interval harian ke atas supaya pergantian DST
+// tidak menggeser batas bucket
+func (i Interval) next(t time.Time) time.Time {
+	switch i {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// BucketVolume adalah agregat transaksi satu bucket untuk satu mata uang.
+// Bucket adalah index bucket pada batas yang dikirim ke repository.
+type BucketVolume struct {
+	Bucket int
+	Count  int64
+	Sum    Money
+}
+
+// StatusVolume adalah agregat transaksi satu status untuk satu mata uang.
+// Sum adalah amount kotor, Net sudah dikurangi refund.
+type StatusVolume struct {
+	Status  TransactionStatus
+	Count   int64
+	Sum     Money
+	Net     Money
+	Largest Money
+}
+
+// BucketStatusVolume adalah StatusVolume untuk satu bucket waktu
+type BucketStatusVolume struct {
+	Bucket int
+	StatusVolume
+}
+
+// IsSettled mengecek status yang dihitung sebagai volume di dashboard
+func (s TransactionStatus) IsSettled() bool {
+	return s == StatusSuccess || s == StatusCaptured || s == StatusPartiallyRefunded
+}
+
+// IsSucceeded mengecek status yang berarti pembayaran pernah berhasil,
+// termasuk yang kemudian di-refund
+func (s TransactionStatus) IsSucceeded() bool {
+	return s.IsSettled() || s == StatusRefunded
+}
+
+// IsDecided mengecek status yang sudah punya hasil akhir (berhasil atau gagal).
+// pending dan authorized belum dihitung di success rate.
+func (s TransactionStatus) IsDecided() bool {
+	return s.IsSucceeded() || s == StatusFailed || s == StatusVoided
+}
+
+// SuccessRate adalah rasio transaksi berhasil terhadap transaksi yang sudah
+// punya hasil akhir, 0 jika belum ada
+func SuccessRate(counts map[TransactionStatus]int64) float64 {
+	var succeeded, decided int64
+	for status, count := range counts {
+		if status.IsSucceeded() {
+			succeeded += count
+		}
+		if status.IsDecided() {
+			decided += count
+		}
+	}
+
+	if decided == 0 {
+		return 0
+	}
+	return float64(succeeded) / float64(decided)
+}
+
+// AmountFilter membatasi transaksi untuk analisa distribusi amount.
+// Status nil berarti hanya transaksi settled.
+type AmountFilter struct {
+	From     time.Time
+	To       time.Time
+	Status   *TransactionStatus
+	Currency Currency
+}
+
+// AmountSummary adalah jumlah transaksi serta amount terkecil dan terbesar
+type AmountSummary struct {
+	Count int64
+	Min   Money
+	Max   Money
+}
+
+// HistogramCount adalah jumlah transaksi pada satu bucket histogram
+type HistogramCount struct {
+	Bucket int
+	Count  int64
+}
+
+// PercentileRank mengembalikan rank (1-based, urut naik) untuk persentil p
+// dari n data dengan metode nearest-rank, 0 jika n nol
+func PercentileRank(p float64, n int64) int64 {
+	if n == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(n)))
+	if rank < 1 {
+		return 1
+	}
+	if rank > n {
+		return n
+	}
+	return rank
+}
+
+// HistogramBoundaries membagi [min, max] ke bucket selebar size yang
+// dimulai dari kelipatan size terdekat di bawah min. Hasilnya n+1 batas
+// untuk n bucket, batas terakhir selalu lebih besar dari max.
+func HistogramBoundaries(min, max, size int64) ([]int64, error) {
+	if size <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	start := min - min%size
+	if min < 0 && min%size != 0 {
+		start -= size
+	}
+
+	boundaries := []int64{start}
+	for cur := start; cur <= max; {
+		if len(boundaries) > MaxBuckets {
+			return nil, ErrTooManyBuckets
+		}
+		cur += size
+		boundaries = append(boundaries, cur)
+	}
+
+	return boundaries, nil
+}
+
+// RankBy adalah ukuran untuk mengurutkan leaderboard user
+type RankBy string
+
+const (
+	RankByVolume RankBy = "volume"
+	RankByCount  RankBy = "count"
+)
+
+// ParseRankBy memvalidasi ?by= dari query string
+func ParseRankBy(s string) (RankBy, error) {
+	switch by := RankBy(s); by {
+	case RankByVolume, RankByCount:
+		return by, nil
+	default:
+		return "", ErrInvalidRankBy
+	}
+}
+
+// TopUsersFilter membatasi leaderboard ke transaksi settled satu mata uang
+// pada [From, To)
+type TopUsersFilter struct {
+	From     time.Time
+	To       time.Time
+	Currency Currency
+	By       RankBy
+	Limit    int
+}
+
+// UserVolume adalah jumlah dan volume bersih transaksi settled satu user
+type UserVolume struct {
+	UserID uint
+	Count  int64
+	Volume Money
+}
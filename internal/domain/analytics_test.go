@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInterval(t *testing.T) {
+	interval, err := ParseInterval("week")
+	assert.NoError(t, err)
+	assert.Equal(t, IntervalWeek, interval)
+
+	_, err = ParseInterval("minute")
+	assert.ErrorIs(t, err, ErrInvalidInterval)
+}
+
+func TestInterval_Buckets(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name     string
+		interval Interval
+		from     time.Time
+		to       time.Time
+		loc      *time.Location
+		want     []time.Time
+	}{
+		{
+			name:     "Hour First Bucket Starts At From",
+			interval: IntervalHour,
+			from:     time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+			to:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			want: []time.Time{
+				time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "Day In Timezone",
+			interval: IntervalDay,
+			from:     time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC),
+			to:       time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC),
+			loc:      jakarta,
+			want: []time.Time{
+				time.Date(2024, 1, 2, 3, 0, 0, 0, jakarta),
+				time.Date(2024, 1, 3, 0, 0, 0, 0, jakarta),
+			},
+		},
+		{
+			name:     "Week Starts Monday",
+			interval: IntervalWeek,
+			from:     time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), // Minggu
+			to:       time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			want: []time.Time{
+				time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "Month",
+			interval: IntervalMonth,
+			from:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			want: []time.Time{
+				time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.interval.Buckets(tt.from, tt.to, tt.loc)
+			assert.NoError(t, err)
+			assert.Len(t, got, len(tt.want))
+			for i := range tt.want {
+				assert.True(t, tt.want[i].Equal(got[i]), "boundary %d: want %s, got %s", i, tt.want[i], got[i])
+			}
+		})
+	}
+
+	t.Run("Aligned From", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		got, err := IntervalDay.Buckets(from, from.AddDate(0, 0, 2), time.UTC)
+		assert.NoError(t, err)
+		assert.Len(t, got, 3)
+		assert.True(t, from.Equal(got[0]))
+		assert.True(t, from.AddDate(0, 0, 1).Equal(got[1]))
+	})
+
+	t.Run("Invalid Range", func(t *testing.T) {
+		now := time.Now()
+		_, err := IntervalDay.Buckets(now, now, time.UTC)
+		assert.ErrorIs(t, err, ErrInvalidTimeRange)
+	})
+
+	t.Run("Too Many Buckets", func(t *testing.T) {
+		from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		_, err := IntervalHour.Buckets(from, from.AddDate(1, 0, 0), time.UTC)
+		assert.ErrorIs(t, err, ErrTooManyBuckets)
+	})
+}
+
+func TestSuccessRate(t *testing.T) {
+	assert.Zero(t, SuccessRate(map[TransactionStatus]int64{StatusPending: 3, StatusAuthorized: 1}))
+	assert.InDelta(t, 0.5, SuccessRate(map[TransactionStatus]int64{
+		StatusSuccess:  1,
+		StatusRefunded: 1,
+		StatusFailed:   1,
+		StatusVoided:   1,
+		StatusPending:  5,
+	}), 1e-9)
+}
+
+func TestPercentileRank(t *testing.T) {
+	assert.Equal(t, int64(0), PercentileRank(50, 0))
+	assert.Equal(t, int64(1), PercentileRank(50, 1))
+	assert.Equal(t, int64(5), PercentileRank(50, 10))
+	assert.Equal(t, int64(10), PercentileRank(95, 10))
+	assert.Equal(t, int64(95), PercentileRank(95, 100))
+	assert.Equal(t, int64(99), PercentileRank(99, 100))
+}
+
+func TestHistogramBoundaries(t *testing.T) {
+	got, err := HistogramBoundaries(1200, 3000, 1000)
+	assert.NoError(t, err)
+	// 3000 tepat di batas sehingga masuk bucket [3000, 4000)
+	assert.Equal(t, []int64{1000, 2000, 3000, 4000}, got)
+
+	got, err = HistogramBoundaries(500, 500, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{500, 501}, got)
+
+	_, err = HistogramBoundaries(0, 100, 0)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = HistogramBoundaries(0, 1_000_000, 1)
+	assert.ErrorIs(t, err, ErrTooManyBuckets)
+}
@@ -4,5 +4,40 @@ import "errors"
 
 var (
 	ErrTransactionNotFound = errors.New("transaction not found")
-	ErrInvalidStatus       = errors.New("invalid transaction status")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrCursorSortMismatch  = errors.New("cursor was issued for a different sort")
+	ErrInvalidSort         = errors.New("invalid sort, fields must be one of amount, currency, created_at, user_id, status, id")
+	ErrNotDeleted          = errors.New("transaction is not deleted")
+	ErrAdminRequired       = errors.New("admin role required")
+	// ErrConcurrentModification berarti transaksi sudah diubah request lain
+	// sejak versi yang dibaca pemanggil
+	ErrConcurrentModification = errors.New("transaction was modified concurrently")
+	ErrInvalidStatus          = errors.New("invalid transaction status")
+	ErrInvalidTransition      = errors.New("invalid transaction status transition")
+	ErrInvalidAmount          = errors.New("invalid amount")
+	ErrInvalidCurrency        = errors.New("unsupported currency")
+	ErrCurrencyMismatch       = errors.New("currency does not match transaction currency")
+	ErrNotRefundable          = errors.New("transaction cannot be refunded in its current status")
+	ErrRefundExceedsAmount    = errors.New("refund exceeds remaining refundable amount")
+
+	ErrAuthorizationExpired     = errors.New("authorization has expired")
+	ErrAuthorizationActive      = errors.New("authorized transaction must be captured or voided before it can be deleted")
+	ErrCaptureExceedsAuthorized = errors.New("capture exceeds authorized amount")
+
+	ErrAccountNotFound  = errors.New("ledger account not found")
+	ErrUnbalancedEntry  = errors.New("journal entry postings do not balance")
+	ErrLedgerImbalanced = errors.New("ledger postings do not sum to zero")
+
+	ErrInsufficientFunds = errors.New("insufficient wallet balance")
+
+	ErrInvalidInterval    = errors.New("invalid interval, must be one of hour, day, week, month")
+	ErrInvalidTimeRange   = errors.New("from must be before to")
+	ErrInvalidAmountRange = errors.New("min_amount must not exceed max_amount")
+	ErrTooManyBuckets     = errors.New("time range contains too many buckets for the interval")
+	ErrInvalidWindow      = errors.New("invalid window, must be one of today, 7d, custom")
+	ErrInvalidRankBy      = errors.New("invalid by, must be one of volume, count")
+
+	ErrIdempotencyKeyNotFound   = errors.New("idempotency key not found")
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
 )
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord menyimpan hasil request yang dikirim dengan Idempotency-Key
+// supaya retry dengan key yang sama bisa dibalas dengan response yang sama.
+type IdempotencyRecord struct {
+	Key          string
+	Fingerprint  string
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// IsCompleted bernilai false selama request pertama masih diproses
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// IsExpired mengecek apakah key sudah melewati TTL
+func (r *IdempotencyRecord) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
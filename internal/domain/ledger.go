@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountType adalah jenis akun ledger
+type AccountType string
+
+const (
+	AccountTypeUserWallet AccountType = "user_wallet"
+	AccountTypeSettlement AccountType = "settlement"
+)
+
+// Account adalah akun ledger, satu akun hanya untuk satu mata uang
+type Account struct {
+	ID        uint
+	Code      string
+	Type      AccountType
+	Currency  Currency
+	CreatedAt time.Time
+}
+
+// UserWalletAccountCode adalah kode akun wallet milik user
+func UserWalletAccountCode(userID uint, currency Currency) string {
+	return fmt.Sprintf("%s:%d:%s", AccountTypeUserWallet, userID, currency)
+}
+
+// SettlementAccountCode adalah kode akun settlement merchant
+func SettlementAccountCode(currency Currency) string {
+	return fmt.Sprintf("%s:%s", AccountTypeSettlement, currency)
+}
+
+// Posting adalah satu baris debit/kredit. Amount positif berarti debit,
+// negatif berarti kredit.
+type Posting struct {
+	ID             uint
+	JournalEntryID uint
+	AccountID      uint
+	Amount         Money
+}
+
+// Debit membuat posting debit (amount positif)
+func Debit(accountID uint, amount Money) Posting {
+	return Posting{AccountID: accountID, Amount: amount}
+}
+
+// Credit membuat posting kredit (amount negatif)
+func Credit(accountID uint, amount Money) Posting {
+	return Posting{
+		AccountID: accountID,
+		Amount:    Money{Minor: -amount.Minor, Currency: amount.Currency},
+	}
+}
+
+// JournalEntry adalah sekumpulan posting yang harus seimbang
+type JournalEntry struct {
+	ID            uint
+	TransactionID uint
+	Description   string
+	Postings      []Posting
+	CreatedAt     time.Time
+}
+
+// NewJournalEntry memvalidasi bahwa total posting per mata uang adalah nol
+func NewJournalEntry(transactionID uint, description string, postings []Posting) (*JournalEntry, error) {
+	if len(postings) < 2 {
+		return nil, ErrUnbalancedEntry
+	}
+
+	sums := make(map[Currency]int64)
+	for _, p := range postings {
+		if p.Amount.Minor == 0 || !p.Amount.Currency.IsValid() {
+			return nil, ErrUnbalancedEntry
+		}
+		sums[p.Amount.Currency] += p.Amount.Minor
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return nil, ErrUnbalancedEntry
+		}
+	}
+
+	return &JournalEntry{
+		TransactionID: transactionID,
+		Description:   description,
+		Postings:      postings,
+		CreatedAt:     time.Now(),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJournalEntry(t *testing.T) {
+	idr := Money{Minor: 1000, Currency: CurrencyIDR}
+	usd := Money{Minor: 1000, Currency: CurrencyUSD}
+
+	t.Run("Balanced", func(t *testing.T) {
+		entry, err := NewJournalEntry(1, "payment", []Posting{Debit(1, idr), Credit(2, idr)})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-1000), entry.Postings[1].Amount.Minor)
+	})
+
+	t.Run("Unbalanced", func(t *testing.T) {
+		_, err := NewJournalEntry(1, "payment", []Posting{Debit(1, idr), Credit(2, Money{Minor: 999, Currency: CurrencyIDR})})
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+	})
+
+	t.Run("Balanced Only Across Currencies", func(t *testing.T) {
+		_, err := NewJournalEntry(1, "fx", []Posting{Debit(1, idr), Credit(2, usd)})
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+	})
+
+	t.Run("Single Posting", func(t *testing.T) {
+		_, err := NewJournalEntry(1, "payment", []Posting{Debit(1, idr)})
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+	})
+
+	t.Run("Zero Amount", func(t *testing.T) {
+		zero := Money{Currency: CurrencyIDR}
+		_, err := NewJournalEntry(1, "payment", []Posting{Debit(1, zero), Credit(2, zero)})
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+	})
+}
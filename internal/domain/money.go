@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Currency adalah kode mata uang ISO 4217
+type Currency string
+
+const (
+	CurrencyIDR Currency = "IDR"
+	CurrencyUSD Currency = "USD"
+	CurrencySGD Currency = "SGD"
+	CurrencyEUR Currency = "EUR"
+	CurrencyJPY Currency = "JPY"
+	CurrencyKWD Currency = "KWD"
+)
+
+// DefaultCurrency dipakai jika request tidak menyebutkan mata uang
+const DefaultCurrency = CurrencyIDR
+
+// currencyExponent adalah jumlah digit desimal (minor unit) per mata uang
+var currencyExponent = map[Currency]int{
+	CurrencyIDR: 2,
+	CurrencyUSD: 2,
+	CurrencySGD: 2,
+	CurrencyEUR: 2,
+	CurrencyJPY: 0,
+	CurrencyKWD: 3,
+}
+
+// Exponent mengembalikan jumlah digit desimal mata uang
+func (c Currency) Exponent() (int, bool) {
+	exp, ok := currencyExponent[c]
+	return exp, ok
+}
+
+// IsValid mengecek apakah mata uang didukung
+func (c Currency) IsValid() bool {
+	_, ok := currencyExponent[c]
+	return ok
+}
+
+// Money adalah nilai uang dalam minor unit (misal sen) beserta mata uangnya
+type Money struct {
+	Minor    int64
+	Currency Currency
+}
+
+// NewMoney membuat Money dari minor unit dengan validasi mata uang
+func NewMoney(minor int64, currency Currency) (Money, error) {
+	if !currency.IsValid() {
+		return Money{}, ErrInvalidCurrency
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// ParseMoney mengubah angka desimal (misal "1000.50") menjadi Money.
+// Jumlah digit desimal tidak boleh melebihi presisi mata uang.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	exp, ok := currency.Exponent()
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.HasPrefix(amount, "-") || strings.HasPrefix(amount, "+") {
+		return Money{}, ErrInvalidAmount
+	}
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" || len(frac) > exp || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, ErrInvalidAmount
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// IsPositive mengecek apakah nilai lebih dari nol
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// DivRound membagi nilai dengan n dan membulatkan ke minor unit terdekat
+// (half away from zero). n nol menghasilkan nol.
+func (m Money) DivRound(n int64) Money {
+	return Money{Minor: roundDiv(m.Minor, n), Currency: m.Currency}
+}
+
+func roundDiv(a, b int64) int64 {
+	if b == 0 {
+		return 0
+	}
+	if a < 0 {
+		return -roundDiv(-a, b)
+	}
+	return (2*a + b) / (2 * b)
+}
+
+// String mengembalikan nilai dalam bentuk desimal, misal "1000.50"
+func (m Money) String() string {
+	exp, _ := m.Currency.Exponent()
+
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := strconv.FormatInt(minor, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// MarshalJSON menulis amount sebagai string desimal agar tidak ada pembulatan
+// float. Money hanya ditulis ke JSON; request membaca amount sebagai
+// json.Number lalu ParseMoney.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string   `json:"amount"`
+		Currency Currency `json:"currency"`
+	}{
+		Amount:   m.String(),
+		Currency: m.Currency,
+	})
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
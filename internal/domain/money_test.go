@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency Currency
+		want     Money
+		wantErr  error
+	}{
+		{name: "Whole IDR", amount: "1000", currency: CurrencyIDR, want: Money{Minor: 100000, Currency: CurrencyIDR}},
+		{name: "Decimal IDR", amount: "1000.5", currency: CurrencyIDR, want: Money{Minor: 100050, Currency: CurrencyIDR}},
+		{name: "JPY No Decimal", amount: "250", currency: CurrencyJPY, want: Money{Minor: 250, Currency: CurrencyJPY}},
+		{name: "KWD Three Decimals", amount: "1.125", currency: CurrencyKWD, want: Money{Minor: 1125, Currency: CurrencyKWD}},
+		{name: "Too Precise", amount: "1.005", currency: CurrencyUSD, wantErr: ErrInvalidAmount},
+		{name: "JPY With Decimal", amount: "1.5", currency: CurrencyJPY, wantErr: ErrInvalidAmount},
+		{name: "Negative", amount: "-10", currency: CurrencyIDR, wantErr: ErrInvalidAmount},
+		{name: "Malformed", amount: "1e3", currency: CurrencyIDR, wantErr: ErrInvalidAmount},
+		{name: "Unknown Currency", amount: "10", currency: Currency("XXX"), wantErr: ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, tt.currency)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "1000.50", Money{Minor: 100050, Currency: CurrencyIDR}.String())
+	assert.Equal(t, "0.05", Money{Minor: 5, Currency: CurrencyUSD}.String())
+	assert.Equal(t, "-0.05", Money{Minor: -5, Currency: CurrencyUSD}.String())
+	assert.Equal(t, "250", Money{Minor: 250, Currency: CurrencyJPY}.String())
+	assert.Equal(t, "0.001", Money{Minor: 1, Currency: CurrencyKWD}.String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	m := Money{Minor: 100050, Currency: CurrencyIDR}
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"1000.50","currency":"IDR"}`, string(b))
+}
+
+func TestMoney_DivRound(t *testing.T) {
+	assert.Equal(t, Money{Minor: 334, Currency: CurrencyIDR}, Money{Minor: 1001, Currency: CurrencyIDR}.DivRound(3))
+	assert.Equal(t, Money{Minor: 2, Currency: CurrencyIDR}, Money{Minor: 3, Currency: CurrencyIDR}.DivRound(2))
+	assert.Equal(t, Money{Minor: -2, Currency: CurrencyIDR}, Money{Minor: -3, Currency: CurrencyIDR}.DivRound(2))
+	assert.Equal(t, Money{Currency: CurrencyIDR}, Money{Minor: 100, Currency: CurrencyIDR}.DivRound(0))
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+const (
+	DefaultPageSize = 10
+	// MaxPageSize membatasi jumlah baris per halaman list transaksi
+	MaxPageSize = 100
+)
+
+// Cursor menunjuk satu baris di list transaksi untuk keyset pagination dan
+// menyimpan nilai baris tersebut untuk setiap field yang bisa di-sort.
+// Backward berarti halaman yang diminta ada sebelum baris ini (prev_cursor).
+type Cursor struct {
+	// Sort adalah urutan saat cursor dibuat, cursor tidak berlaku untuk sort lain
+	Sort      string            `json:"s,omitempty"`
+	CreatedAt time.Time         `json:"t"`
+	ID        uint              `json:"id"`
+	Amount    int64             `json:"a,omitempty"`
+	Currency  Currency          `json:"c,omitempty"`
+	UserID    uint              `json:"u,omitempty"`
+	Status    TransactionStatus `json:"st,omitempty"`
+	Backward  bool              `json:"b,omitempty"`
+}
+
+// NewCursor membuat cursor yang menunjuk ke tx untuk urutan sort,
+// sort kosong berarti DefaultSort
+func NewCursor(tx Transaction, sort Sort, backward bool) *Cursor {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+	return &Cursor{
+		Sort:      sort.String(),
+		CreatedAt: tx.CreatedAt,
+		ID:        tx.ID,
+		Amount:    tx.Amount.Minor,
+		Currency:  tx.Amount.Currency,
+		UserID:    tx.UserID,
+		Status:    tx.Status,
+		Backward:  backward,
+	}
+}
+
+// Value adalah nilai baris cursor untuk field f
+func (c Cursor) Value(f SortField) any {
+	switch f {
+	case SortAmount:
+		return c.Amount
+	case SortCurrency:
+		return string(c.Currency)
+	case SortCreatedAt:
+		return c.CreatedAt
+	case SortUserID:
+		return c.UserID
+	case SortStatus:
+		return string(c.Status)
+	default:
+		return c.ID
+	}
+}
+
+// Encode mengubah cursor menjadi string opaque yang aman untuk query string.
+// Tipe plain dipakai supaya json.Marshal tidak memanggil MarshalJSON lagi.
+func (c Cursor) Encode() string {
+	type plain Cursor
+	data, _ := json.Marshal(plain(c))
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// MarshalJSON menulis cursor sebagai string opaque di response
+func (c Cursor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Encode())
+}
+
+// DecodeCursor membaca cursor dari query string, ErrInvalidCursor jika rusak
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	type plain Cursor
+	var c plain
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := Cursor(c)
+	// cursor lama tidak menyimpan sort dan selalu memakai DefaultSort
+	if cursor.Sort == "" {
+		cursor.Sort = DefaultSort.String()
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Refund adalah pengembalian dana (penuh atau sebagian) atas transaksi success
+type Refund struct {
+	ID            uint
+	TransactionID uint
+	Amount        Money
+	Reason        string
+	CreatedAt     time.Time
+}
+
+// NewRefund adalah constructor refund baru
+func NewRefund(transactionID uint, amount Money, reason string) *Refund {
+	return &Refund{
+		TransactionID: transactionID,
+		Amount:        amount,
+		Reason:        reason,
+		CreatedAt:     time.Now(),
+	}
+}
@@ -1,27 +1,152 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // TransactionFilter untuk query list transaksi
 type TransactionFilter struct {
-	UserID *uint
-	Status *TransactionStatus
-	From   *time.Time
-	To     *time.Time
-	Limit  int
-	Offset int
+	// UserIDs dan Statuses kosong berarti semua
+	UserIDs  []uint
+	Statuses []TransactionStatus
+	// From dan To inklusif. ToExclusive membuat To eksklusif, dipakai untuk
+	// tanggal saja yang sudah diperluas ke awal hari berikutnya.
+	From        *time.Time
+	To          *time.Time
+	ToExclusive bool
+	// MinAmount dan MaxAmount inklusif dan hanya cocok dengan transaksi
+	// bermata uang sama
+	MinAmount *Money
+	MaxAmount *Money
+	Limit     int
+	Offset    int
+	// Sort kosong berarti DefaultSort
+	Sort Sort
+	// Cursor mengaktifkan keyset pagination sesuai Sort; Offset diabaikan
+	Cursor *Cursor
+	// IncludeDeleted ikut mengambil transaksi yang sudah di-soft delete
+	IncludeDeleted bool
 }
 
-// TransactionRepository adalah kontrak repository
+// Validate mengecek rentang waktu dan amount pada filter
+func (f TransactionFilter) Validate() error {
+	if f.From != nil && f.To != nil {
+		if f.From.After(*f.To) || (f.ToExclusive && f.From.Equal(*f.To)) {
+			return ErrInvalidTimeRange
+		}
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil {
+		if f.MinAmount.Currency != f.MaxAmount.Currency {
+			return ErrCurrencyMismatch
+		}
+		if f.MinAmount.Minor > f.MaxAmount.Minor {
+			return ErrInvalidAmountRange
+		}
+	}
+	return nil
+}
+
+// TransactionRepository adalah kontrak repository.
+// Semua method menerima context agar query ikut batal saat request dibatalkan.
 type TransactionRepository interface {
-	Create(tx *Transaction) error
-	FindByID(id uint) (*Transaction, error)
-	FindAll(filter TransactionFilter) ([]Transaction, error)
-	Update(tx *Transaction) error
-	Delete(id uint) error
-
-	// Dashboard queries
-	TotalSuccessToday() (float64, error)
-	AverageAmountPerUser() (float64, error)
-	Latest(limit int) ([]Transaction, error)
+	Create(ctx context.Context, tx *Transaction) error
+	FindByID(ctx context.Context, id uint) (*Transaction, error)
+	// FindByIDForUpdate mengunci baris transaksi, dipakai di dalam Transactor
+	FindByIDForUpdate(ctx context.Context, id uint) (*Transaction, error)
+	FindAll(ctx context.Context, filter TransactionFilter) ([]Transaction, error)
+	// Count menghitung transaksi yang cocok dengan filter, Limit, Offset dan
+	// Cursor diabaikan
+	Count(ctx context.Context, filter TransactionFilter) (int64, error)
+	// Update melakukan compare-and-swap pada tx.Version dan menaikkan versinya.
+	// ErrConcurrentModification jika versi di database sudah berbeda.
+	Update(ctx context.Context, tx *Transaction) error
+	// Delete adalah soft delete, baris tetap ada sampai di-purge
+	Delete(ctx context.Context, id uint) error
+	// Restore membatalkan soft delete, ErrNotDeleted jika belum dihapus
+	Restore(ctx context.Context, id uint) error
+	// PurgeDeleted menghapus permanen transaksi yang di-soft delete sebelum before
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// FindExpiredAuthorizations mengambil transaksi authorized yang
+	// authorization-nya sudah expired pada now, maksimal limit baris
+	FindExpiredAuthorizations(ctx context.Context, now time.Time, limit int) ([]Transaction, error)
+	// History mengambil audit trail transaksi, termasuk yang sudah dihapus
+	History(ctx context.Context, id uint) ([]TransactionEvent, error)
+
+	// Dashboard queries, agregat dikembalikan per mata uang
+	// TotalSuccessToday memakai batas hari (00:00 - 24:00) di zona waktu loc
+	TotalSuccessToday(ctx context.Context, loc *time.Location) ([]Money, error)
+	// AverageTransactionAmount adalah rata-rata amount per transaksi
+	AverageTransactionAmount(ctx context.Context) ([]Money, error)
+	// AverageTotalPerUser adalah rata-rata dari total amount setiap user
+	AverageTotalPerUser(ctx context.Context) ([]Money, error)
+	Latest(ctx context.Context, limit int) ([]Transaction, error)
+	// VolumeByBucket mengelompokkan transaksi ke bucket [boundaries[i],
+	// boundaries[i+1]). Status nil berarti hanya transaksi yang settled.
+	// Bucket tanpa transaksi tidak dikembalikan.
+	VolumeByBucket(ctx context.Context, boundaries []time.Time, status *TransactionStatus) ([]BucketVolume, error)
+	// StatusVolumeByBucket seperti VolumeByBucket untuk semua status,
+	// dikelompokkan per bucket dan status dalam satu query
+	StatusVolumeByBucket(ctx context.Context, boundaries []time.Time) ([]BucketStatusVolume, error)
+
+	// AmountSummary menghitung jumlah serta amount minimum dan maksimum
+	AmountSummary(ctx context.Context, filter AmountFilter) (AmountSummary, error)
+	// AmountsAtRanks mengambil amount pada setiap urutan rank (1-based, urut
+	// naik) dalam satu query; hasilnya sejajar dengan ranks
+	AmountsAtRanks(ctx context.Context, filter AmountFilter, ranks []int64) ([]Money, error)
+	// AmountHistogram menghitung transaksi per bucket amount
+	// [boundaries[i], boundaries[i+1]). Bucket kosong tidak dikembalikan.
+	AmountHistogram(ctx context.Context, filter AmountFilter, boundaries []int64) ([]HistogramCount, error)
+
+	// TopUsers mengurutkan user berdasarkan volume atau jumlah transaksi
+	// settled, user_id terkecil menang jika seri
+	TopUsers(ctx context.Context, filter TopUsersFilter) ([]UserVolume, error)
+
+	// UserVolumeByStatus mengelompokkan seluruh transaksi user per status
+	UserVolumeByStatus(ctx context.Context, userID uint) ([]StatusVolume, error)
+	// UserActivity mengembalikan waktu transaksi pertama dan terakhir user,
+	// nil jika user belum punya transaksi
+	UserActivity(ctx context.Context, userID uint) (first, last *time.Time, err error)
+}
+
+// Transactor menjalankan fn dalam satu database transaction. Repository yang
+// dipanggil dengan ctx dari fn otomatis ikut transaction yang sama.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// RefundRepository menyimpan refund yang terhubung ke transaksi
+type RefundRepository interface {
+	Create(ctx context.Context, refund *Refund) error
+	FindByTransactionID(ctx context.Context, transactionID uint) ([]Refund, error)
+}
+
+// LedgerRepository menyimpan akun, journal entry dan posting double-entry
+type LedgerRepository interface {
+	FindOrCreateAccount(ctx context.Context, code string, accountType AccountType, currency Currency) (*Account, error)
+	FindAccountByID(ctx context.Context, id uint) (*Account, error)
+	// CreateEntry menyimpan entry beserta seluruh posting-nya
+	CreateEntry(ctx context.Context, entry *JournalEntry) error
+	AccountBalance(ctx context.Context, accountID uint) (int64, error)
+	// PostingTotals adalah jumlah semua posting per mata uang, harus nol
+	PostingTotals(ctx context.Context) ([]Money, error)
+}
+
+// IdempotencyRepository menyimpan Idempotency-Key beserta response aslinya
+type IdempotencyRepository interface {
+	// Reserve menyimpan key baru, false jika key sudah ada
+	Reserve(ctx context.Context, rec *IdempotencyRecord) (bool, error)
+	FindByKey(ctx context.Context, key string) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, body []byte) error
+	Delete(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// WalletRepository menyimpan saldo wallet per user dan mata uang
+type WalletRepository interface {
+	// FindForUpdate mengambil wallet dan menguncinya sampai database
+	// transaction selesai. Wallet yang belum ada dibuat dengan saldo nol.
+	FindForUpdate(ctx context.Context, userID uint, currency Currency) (*Wallet, error)
+	FindByUserID(ctx context.Context, userID uint) ([]Wallet, error)
+	Update(ctx context.Context, wallet *Wallet) error
 }
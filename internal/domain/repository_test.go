@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionFilter_Validate(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	idr := func(minor int64) *Money { return &Money{Minor: minor, Currency: CurrencyIDR} }
+
+	tests := []struct {
+		name    string
+		filter  TransactionFilter
+		wantErr error
+	}{
+		{name: "Empty", filter: TransactionFilter{}},
+		{name: "Open Ended", filter: TransactionFilter{From: day(1), MinAmount: idr(100)}},
+		{name: "Valid Ranges", filter: TransactionFilter{From: day(1), To: day(2), MinAmount: idr(100), MaxAmount: idr(100)}},
+		{name: "Single Instant", filter: TransactionFilter{From: day(2), To: day(2)}},
+		{name: "Empty Time Range", filter: TransactionFilter{From: day(2), To: day(2), ToExclusive: true}, wantErr: ErrInvalidTimeRange},
+		{name: "Inverted Time Range", filter: TransactionFilter{From: day(3), To: day(2)}, wantErr: ErrInvalidTimeRange},
+		{name: "Inverted Amount Range", filter: TransactionFilter{MinAmount: idr(200), MaxAmount: idr(100)}, wantErr: ErrInvalidAmountRange},
+		{
+			name:    "Mixed Currency",
+			filter:  TransactionFilter{MinAmount: idr(100), MaxAmount: &Money{Minor: 200, Currency: CurrencyUSD}},
+			wantErr: ErrCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.filter.Validate(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "strings"
+
+// SortField adalah field list transaksi yang boleh dipakai di ?sort=
+type SortField string
+
+const (
+	// SortAmount mengurutkan amount dalam satuan minor. Amount beda mata uang
+	// tidak bisa dibandingkan, jadi ParseSort selalu menaruh SortCurrency
+	// sebelum SortAmount.
+	SortAmount    SortField = "amount"
+	SortCurrency  SortField = "currency"
+	SortCreatedAt SortField = "created_at"
+	SortUserID    SortField = "user_id"
+	SortStatus    SortField = "status"
+	SortID        SortField = "id"
+)
+
+func (f SortField) valid() bool {
+	switch f {
+	case SortAmount, SortCurrency, SortCreatedAt, SortUserID, SortStatus, SortID:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortKey adalah satu field urutan, Desc untuk descending
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// Sort adalah urutan list transaksi. Sort hasil ParseSort selalu memuat id
+// sebagai tiebreaker supaya urutannya stabil untuk keyset pagination.
+type Sort []SortKey
+
+// DefaultSort adalah urutan list tanpa ?sort=, transaksi terbaru dulu
+var DefaultSort = Sort{{Field: SortCreatedAt, Desc: true}, {Field: SortID, Desc: true}}
+
+// ParseSort membaca ?sort= seperti "-amount,created_at", prefix "-" berarti
+// descending. currency (ascending) disisipkan sebelum amount jika belum
+// disebut lebih dulu, sehingga "-amount" menjadi "currency,-amount,-id". id
+// ditambahkan di akhir dengan arah field terakhir jika belum ada. String
+// kosong berarti DefaultSort.
+func ParseSort(s string) (Sort, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultSort, nil
+	}
+
+	var sort Sort
+	seen := make(map[SortField]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		var key SortKey
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key.Desc = true
+			part = name
+		}
+		key.Field = SortField(part)
+
+		if !key.Field.valid() || seen[key.Field] {
+			return nil, ErrInvalidSort
+		}
+		if key.Field == SortAmount && !seen[SortCurrency] {
+			seen[SortCurrency] = true
+			sort = append(sort, SortKey{Field: SortCurrency})
+		}
+		seen[key.Field] = true
+		sort = append(sort, key)
+	}
+
+	if !seen[SortID] {
+		sort = append(sort, SortKey{Field: SortID, Desc: sort[len(sort)-1].Desc})
+	}
+	return sort, nil
+}
+
+// String adalah bentuk ?sort= dari s, dipakai untuk mencocokkan cursor
+func (s Sort) String() string {
+	parts := make([]string, len(s))
+	for i, key := range s {
+		parts[i] = string(key.Field)
+		if key.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
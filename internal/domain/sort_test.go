@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "Empty Is Default", input: "", want: "-created_at,-id"},
+		{name: "Appends Id Tiebreaker", input: "-created_at", want: "-created_at,-id"},
+		{name: "Amount Grouped By Currency", input: "-amount", want: "currency,-amount,-id"},
+		{name: "Tiebreaker Follows Last Field", input: "-amount,created_at", want: "currency,-amount,created_at,id"},
+		{name: "Explicit Currency Kept", input: "-currency,amount", want: "-currency,amount,id"},
+		{name: "Currency After Amount", input: "amount,currency", wantErr: ErrInvalidSort},
+		{name: "Explicit Id Kept", input: "id,status", want: "id,status"},
+		{name: "Spaces Trimmed", input: " user_id , -status ", want: "user_id,-status,-id"},
+		{name: "Unknown Field", input: "amount_minor", wantErr: ErrInvalidSort},
+		{name: "Injection", input: "created_at;drop table transactions", wantErr: ErrInvalidSort},
+		{name: "Duplicate Field", input: "amount,-amount", wantErr: ErrInvalidSort},
+		{name: "Empty Field", input: "amount,", wantErr: ErrInvalidSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.input)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
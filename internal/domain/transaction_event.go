@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TransactionEventType adalah jenis mutasi transaksi yang dicatat di audit trail
+type TransactionEventType string
+
+const (
+	EventTransactionCreated  TransactionEventType = "created"
+	EventTransactionUpdated  TransactionEventType = "updated"
+	EventTransactionDeleted  TransactionEventType = "deleted"
+	EventTransactionRestored TransactionEventType = "restored"
+	EventTransactionPurged   TransactionEventType = "purged"
+)
+
+// SystemActor dipakai jika mutasi tidak berasal dari request HTTP
+const SystemActor = "system"
+
+// TransactionEvent adalah satu baris audit trail yang tidak pernah diubah.
+// Old* kosong untuk event created, New* kosong untuk event deleted dan purged.
+type TransactionEvent struct {
+	ID            uint
+	TransactionID uint
+	Type          TransactionEventType
+	OldStatus     TransactionStatus
+	NewStatus     TransactionStatus
+	OldAmount     Money
+	NewAmount     Money
+	Actor         string
+	RequestID     string
+	CreatedAt     time.Time
+}
+
+// AuditInfo adalah siapa dan request mana yang melakukan mutasi
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+	// Admin berarti request membawa token admin yang valid sehingga boleh
+	// melihat dan memulihkan transaksi terhapus
+	Admin bool
+}
+
+type auditInfoKey struct{}
+
+// WithAuditInfo menyimpan AuditInfo di ctx untuk dicatat repository
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFromContext mengambil AuditInfo dari ctx, actor default SystemActor
+func AuditInfoFromContext(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = SystemActor
+	}
+	return info
+}
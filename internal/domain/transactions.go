@@ -9,42 +9,211 @@ const (
 	StatusPending TransactionStatus = "pending"
 	StatusSuccess TransactionStatus = "success"
 	StatusFailed  TransactionStatus = "failed"
+
+	// Status refund hanya bisa dicapai lewat Refund, bukan UpdateStatus
+	StatusPartiallyRefunded TransactionStatus = "partially_refunded"
+	StatusRefunded          TransactionStatus = "refunded"
+
+	// Status two-phase hanya bisa dicapai lewat Authorize, Capture dan Void
+	StatusAuthorized TransactionStatus = "authorized"
+	StatusCaptured   TransactionStatus = "captured"
+	StatusVoided     TransactionStatus = "voided"
 )
 
+// Statuses adalah semua status transaksi yang valid, dipakai untuk pesan
+// validasi yang menyebutkan nilai yang diizinkan
+var Statuses = []TransactionStatus{
+	StatusPending, StatusSuccess, StatusFailed, StatusPartiallyRefunded, StatusRefunded,
+	StatusAuthorized, StatusCaptured, StatusVoided,
+}
+
+// ParseStatus memvalidasi status dari input, ErrInvalidStatus jika tidak dikenal
+func ParseStatus(s string) (TransactionStatus, error) {
+	status := TransactionStatus(s)
+	if !status.IsValid() {
+		return "", ErrInvalidStatus
+	}
+	return status, nil
+}
+
+// IsValid mengecek apakah s adalah salah satu Statuses
+func (s TransactionStatus) IsValid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 // Transaction adalah entity utama domain
 type Transaction struct {
-	ID        uint
-	UserID    uint
-	Amount    float64
-	Status    TransactionStatus
+	ID       uint
+	UserID   uint
+	Amount   Money
+	Refunded Money
+	// Authorized adalah amount yang ditahan saat Authorize
+	Authorized             Money
+	AuthorizationExpiresAt *time.Time
+	Status                 TransactionStatus
+	// Version naik setiap kali transaksi di-update, dipakai untuk
+	// optimistic concurrency (ETag / If-Match)
+	Version   uint
 	CreatedAt time.Time
+	// DeletedAt terisi jika transaksi sudah di-soft delete
+	DeletedAt *time.Time
 }
 
 // NewTransaction adalah constructor transaksi baru
-func NewTransaction(userID uint, amount float64) *Transaction {
+func NewTransaction(userID uint, amount Money) *Transaction {
 	return &Transaction{
-		UserID:    userID,
-		Amount:    amount,
-		Status:    StatusPending,
-		CreatedAt: time.Now(),
+		UserID:     userID,
+		Amount:     amount,
+		Refunded:   Money{Currency: amount.Currency},
+		Authorized: Money{Currency: amount.Currency},
+		Status:     StatusPending,
+		Version:    1,
+		CreatedAt:  time.Now(),
 	}
 }
 
+// allowedTransitions adalah tabel transisi status yang diizinkan.
+// Status yang tidak punya entry (success, failed) adalah status final.
+var allowedTransitions = map[TransactionStatus][]TransactionStatus{
+	StatusPending: {StatusSuccess, StatusFailed},
+}
+
 // UpdateStatus mengubah status transaksi dengan validasi
 func (t *Transaction) UpdateStatus(status TransactionStatus) error {
-	if !isValidStatus(status) {
+	if !status.IsValid() {
 		return ErrInvalidStatus
 	}
 
+	if !canTransition(t.Status, status) {
+		return ErrInvalidTransition
+	}
+
 	t.Status = status
 	return nil
 }
 
-func isValidStatus(status TransactionStatus) bool {
-	switch status {
-	case StatusPending, StatusSuccess, StatusFailed:
-		return true
+// RefundableAmount adalah sisa amount yang masih bisa di-refund
+func (t *Transaction) RefundableAmount() Money {
+	return Money{
+		Minor:    t.Amount.Minor - t.Refunded.Minor,
+		Currency: t.Amount.Currency,
+	}
+}
+
+// Refund mengurangi sisa amount transaksi dan mengubah status menjadi
+// partially_refunded atau refunded. Hanya transaksi success, captured atau
+// partially_refunded yang bisa di-refund.
+func (t *Transaction) Refund(amount Money) error {
+	if t.Status != StatusSuccess && t.Status != StatusCaptured && t.Status != StatusPartiallyRefunded {
+		return ErrNotRefundable
+	}
+
+	if amount.Currency != t.Amount.Currency {
+		return ErrCurrencyMismatch
+	}
+
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	if amount.Minor > t.RefundableAmount().Minor {
+		return ErrRefundExceedsAmount
+	}
+
+	t.Refunded = Money{
+		Minor:    t.Refunded.Minor + amount.Minor,
+		Currency: t.Amount.Currency,
+	}
+
+	if t.Refunded.Minor == t.Amount.Minor {
+		t.Status = StatusRefunded
+	} else {
+		t.Status = StatusPartiallyRefunded
+	}
+
+	return nil
+}
+
+// Authorize menahan amount transaksi pending sampai expiresAt
+func (t *Transaction) Authorize(expiresAt time.Time) error {
+	if t.Status != StatusPending {
+		return ErrInvalidTransition
+	}
+
+	t.Authorized = t.Amount
+	t.AuthorizationExpiresAt = &expiresAt
+	t.Status = StatusAuthorized
+	return nil
+}
+
+// Capture menagih sebagian atau seluruh amount yang sudah di-authorize.
+// Amount transaksi menjadi amount yang di-capture; sisanya dilepas.
+func (t *Transaction) Capture(amount Money, now time.Time) error {
+	if t.Status != StatusAuthorized {
+		return ErrInvalidTransition
+	}
+
+	if t.IsAuthorizationExpired(now) {
+		return ErrAuthorizationExpired
+	}
+
+	if amount.Currency != t.Authorized.Currency {
+		return ErrCurrencyMismatch
+	}
+
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	if amount.Minor > t.Authorized.Minor {
+		return ErrCaptureExceedsAuthorized
+	}
+
+	t.Amount = amount
+	t.Status = StatusCaptured
+	return nil
+}
+
+// Void membatalkan authorization sehingga seluruh amount dilepas
+func (t *Transaction) Void() error {
+	if t.Status != StatusAuthorized {
+		return ErrInvalidTransition
+	}
+
+	t.Status = StatusVoided
+	return nil
+}
+
+// ReleasedAmount adalah bagian authorization yang tidak di-capture
+func (t *Transaction) ReleasedAmount() Money {
+	switch t.Status {
+	case StatusVoided:
+		return t.Authorized
+	case StatusCaptured:
+		return Money{
+			Minor:    t.Authorized.Minor - t.Amount.Minor,
+			Currency: t.Authorized.Currency,
+		}
 	default:
-		return false
+		return Money{Currency: t.Authorized.Currency}
+	}
+}
+
+// IsAuthorizationExpired mengecek apakah authorization sudah lewat batas waktu
+func (t *Transaction) IsAuthorizationExpired(now time.Time) bool {
+	return t.AuthorizationExpiresAt != nil && !now.Before(*t.AuthorizationExpiresAt)
+}
+
+func canTransition(from, to TransactionStatus) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
 	}
+	return false
 }
@@ -2,13 +2,14 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewTransaction(t *testing.T) {
 	userID := uint(1)
-	amount := 50000.0
+	amount := Money{Minor: 5000000, Currency: CurrencyIDR}
 
 	tx := NewTransaction(userID, amount)
 
@@ -44,6 +45,30 @@ func TestUpdateStatus(t *testing.T) {
 			updateStatus:  TransactionStatus("iseng"),
 			wantErr:       ErrInvalidStatus,
 		},
+		{
+			name:          "Success to Pending - Invalid Transition",
+			initialStatus: StatusSuccess,
+			updateStatus:  StatusPending,
+			wantErr:       ErrInvalidTransition,
+		},
+		{
+			name:          "Success to Failed - Invalid Transition",
+			initialStatus: StatusSuccess,
+			updateStatus:  StatusFailed,
+			wantErr:       ErrInvalidTransition,
+		},
+		{
+			name:          "Failed to Success - Invalid Transition",
+			initialStatus: StatusFailed,
+			updateStatus:  StatusSuccess,
+			wantErr:       ErrInvalidTransition,
+		},
+		{
+			name:          "Pending to Pending - Invalid Transition",
+			initialStatus: StatusPending,
+			updateStatus:  StatusPending,
+			wantErr:       ErrInvalidTransition,
+		},
 	}
 
 	for _, tt := range tests {
@@ -60,3 +85,112 @@ func TestUpdateStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range Statuses {
+		got, err := ParseStatus(string(s))
+		assert.NoError(t, err)
+		assert.Equal(t, s, got)
+	}
+
+	for _, s := range []string{"", "SUCCESS", "sucess", "deleted"} {
+		_, err := ParseStatus(s)
+		assert.ErrorIs(t, err, ErrInvalidStatus, s)
+	}
+}
+
+func TestRefund(t *testing.T) {
+	idr := func(minor int64) Money { return Money{Minor: minor, Currency: CurrencyIDR} }
+
+	t.Run("Partial Then Full", func(t *testing.T) {
+		tx := &Transaction{Amount: idr(1000), Refunded: idr(0), Status: StatusSuccess}
+
+		assert.NoError(t, tx.Refund(idr(300)))
+		assert.Equal(t, StatusPartiallyRefunded, tx.Status)
+		assert.Equal(t, idr(700), tx.RefundableAmount())
+
+		assert.NoError(t, tx.Refund(idr(700)))
+		assert.Equal(t, StatusRefunded, tx.Status)
+		assert.Equal(t, idr(0), tx.RefundableAmount())
+
+		assert.ErrorIs(t, tx.Refund(idr(1)), ErrNotRefundable)
+	})
+
+	t.Run("Exceeds Remaining", func(t *testing.T) {
+		tx := &Transaction{Amount: idr(1000), Refunded: idr(600), Status: StatusPartiallyRefunded}
+
+		assert.ErrorIs(t, tx.Refund(idr(401)), ErrRefundExceedsAmount)
+		assert.Equal(t, StatusPartiallyRefunded, tx.Status)
+	})
+
+	t.Run("Pending Or Failed", func(t *testing.T) {
+		for _, status := range []TransactionStatus{StatusPending, StatusFailed} {
+			tx := &Transaction{Amount: idr(1000), Status: status}
+			assert.ErrorIs(t, tx.Refund(idr(100)), ErrNotRefundable)
+		}
+	})
+
+	t.Run("Currency Mismatch", func(t *testing.T) {
+		tx := &Transaction{Amount: idr(1000), Status: StatusSuccess}
+		assert.ErrorIs(t, tx.Refund(Money{Minor: 100, Currency: CurrencyUSD}), ErrCurrencyMismatch)
+	})
+
+	t.Run("Non Positive", func(t *testing.T) {
+		tx := &Transaction{Amount: idr(1000), Status: StatusSuccess}
+		assert.ErrorIs(t, tx.Refund(idr(0)), ErrInvalidAmount)
+	})
+
+	t.Run("Refund Status Not Reachable Via UpdateStatus", func(t *testing.T) {
+		tx := &Transaction{Amount: idr(1000), Status: StatusSuccess}
+		assert.ErrorIs(t, tx.UpdateStatus(StatusRefunded), ErrInvalidTransition)
+	})
+}
+
+func TestAuthorizeCaptureVoid(t *testing.T) {
+	idr := func(minor int64) Money { return Money{Minor: minor, Currency: CurrencyIDR} }
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Partial Capture", func(t *testing.T) {
+		tx := NewTransaction(1, idr(10000))
+
+		assert.NoError(t, tx.Authorize(now.Add(time.Hour)))
+		assert.Equal(t, StatusAuthorized, tx.Status)
+		assert.Equal(t, idr(10000), tx.Authorized)
+
+		assert.ErrorIs(t, tx.Capture(idr(10001), now), ErrCaptureExceedsAuthorized)
+		assert.NoError(t, tx.Capture(idr(6000), now))
+		assert.Equal(t, StatusCaptured, tx.Status)
+		assert.Equal(t, idr(6000), tx.Amount)
+		assert.Equal(t, idr(4000), tx.ReleasedAmount())
+
+		// Transaksi captured bisa di-refund sebesar amount yang di-capture
+		assert.ErrorIs(t, tx.Refund(idr(6001)), ErrRefundExceedsAmount)
+		assert.NoError(t, tx.Refund(idr(6000)))
+		assert.Equal(t, StatusRefunded, tx.Status)
+	})
+
+	t.Run("Capture After Expiry", func(t *testing.T) {
+		tx := NewTransaction(1, idr(10000))
+		assert.NoError(t, tx.Authorize(now))
+
+		assert.True(t, tx.IsAuthorizationExpired(now))
+		assert.ErrorIs(t, tx.Capture(idr(10000), now), ErrAuthorizationExpired)
+	})
+
+	t.Run("Void", func(t *testing.T) {
+		tx := NewTransaction(1, idr(10000))
+		assert.ErrorIs(t, tx.Void(), ErrInvalidTransition)
+
+		assert.NoError(t, tx.Authorize(now.Add(time.Hour)))
+		assert.NoError(t, tx.Void())
+		assert.Equal(t, StatusVoided, tx.Status)
+		assert.Equal(t, idr(10000), tx.ReleasedAmount())
+
+		assert.ErrorIs(t, tx.Capture(idr(10000), now), ErrInvalidTransition)
+	})
+
+	t.Run("Not Reachable Through UpdateStatus", func(t *testing.T) {
+		tx := NewTransaction(1, idr(10000))
+		assert.ErrorIs(t, tx.UpdateStatus(StatusCaptured), ErrInvalidTransition)
+	})
+}
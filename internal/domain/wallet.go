@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Wallet adalah saldo user untuk satu mata uang
+type Wallet struct {
+	ID        uint
+	UserID    uint
+	Balance   Money
+	UpdatedAt time.Time
+}
+
+// NewWallet membuat wallet kosong untuk user dan mata uang tertentu
+func NewWallet(userID uint, currency Currency) *Wallet {
+	return &Wallet{
+		UserID:    userID,
+		Balance:   Money{Currency: currency},
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Debit mengurangi saldo. Saldo tidak boleh menjadi negatif.
+func (w *Wallet) Debit(amount Money) error {
+	if err := w.checkAmount(amount); err != nil {
+		return err
+	}
+	if amount.Minor > w.Balance.Minor {
+		return ErrInsufficientFunds
+	}
+
+	w.Balance.Minor -= amount.Minor
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// Credit menambah saldo, misal refund atau hold yang dilepas
+func (w *Wallet) Credit(amount Money) error {
+	if err := w.checkAmount(amount); err != nil {
+		return err
+	}
+
+	w.Balance.Minor += amount.Minor
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *Wallet) checkAmount(amount Money) error {
+	if amount.Currency != w.Balance.Currency {
+		return ErrCurrencyMismatch
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallet_DebitCredit(t *testing.T) {
+	idr := func(minor int64) Money { return Money{Minor: minor, Currency: CurrencyIDR} }
+
+	w := NewWallet(1, CurrencyIDR)
+
+	assert.ErrorIs(t, w.Debit(idr(1)), ErrInsufficientFunds)
+	assert.NoError(t, w.Credit(idr(1000)))
+	assert.NoError(t, w.Debit(idr(1000)))
+	assert.Equal(t, idr(0), w.Balance)
+
+	assert.ErrorIs(t, w.Credit(idr(0)), ErrInvalidAmount)
+	assert.ErrorIs(t, w.Credit(Money{Minor: 10, Currency: CurrencyUSD}), ErrCurrencyMismatch)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/service"
+)
+
+type AuthorizationHandler struct {
+	service *service.AuthorizationService
+	logger  *zap.Logger
+}
+
+func NewAuthorizationHandler(s *service.AuthorizationService, logger *zap.Logger) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		service: s,
+		logger:  logger,
+	}
+}
+
+// CaptureRequest: amount kosong berarti capture seluruh amount yang di-authorize
+type CaptureRequest struct {
+	Amount   json.Number     `json:"amount"`
+	Currency domain.Currency `json:"currency"`
+}
+
+func (h *AuthorizationHandler) Authorize(c *gin.Context) {
+	id, ok := h.transactionID(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.service.Authorize(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, "authorize", id, err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction authorized",
+		zap.Uint("transaction_id", tx.ID),
+		zap.String("amount", tx.Authorized.String()),
+		zap.Timep("expires_at", tx.AuthorizationExpiresAt),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tx,
+	})
+}
+
+func (h *AuthorizationHandler) Capture(c *gin.Context) {
+	id, ok := h.transactionID(c)
+	if !ok {
+		return
+	}
+
+	// Body boleh kosong untuk capture penuh
+	var req CaptureRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			requestLogger(c, h.logger).Warn("invalid capture request",
+				zap.Uint("transaction_id", id),
+				zap.Error(err),
+			)
+			bindingFailed(c, err)
+			return
+		}
+	}
+
+	tx, err := h.service.Capture(c.Request.Context(), id, req.Amount.String(), req.Currency)
+	if err != nil {
+		h.respondError(c, "capture", id, err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction captured",
+		zap.Uint("transaction_id", tx.ID),
+		zap.String("amount", tx.Amount.String()),
+		zap.String("released", tx.ReleasedAmount().String()),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tx,
+	})
+}
+
+func (h *AuthorizationHandler) Void(c *gin.Context) {
+	id, ok := h.transactionID(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.service.Void(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, "void", id, err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction voided",
+		zap.Uint("transaction_id", tx.ID),
+		zap.String("released", tx.ReleasedAmount().String()),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tx,
+	})
+}
+
+func (h *AuthorizationHandler) transactionID(c *gin.Context) (uint, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *AuthorizationHandler) respondError(c *gin.Context, action string, id uint, err error) {
+	if isServerError(err) {
+		requestLogger(c, h.logger).Error("failed to "+action+" transaction",
+			zap.Uint("transaction_id", id),
+			zap.Error(err),
+		)
+	} else {
+		requestLogger(c, h.logger).Warn(action+" rejected",
+			zap.Uint("transaction_id", id),
+			zap.Error(err),
+		)
+	}
+
+	_ = c.Error(err)
+}
@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/service"
+)
+
+func setupAuthorizationRouter(txRepo *mockTransactionRepo, wallets *mockWalletRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	svc := service.NewAuthorizationService(
+		mockTransactor{},
+		txRepo,
+		service.NewLedgerService(&mockLedgerRepo{}),
+		service.NewWalletService(wallets),
+		service.NewBroadcaster(1, zap.NewNop()),
+		time.Hour,
+	)
+	h := handler.NewAuthorizationHandler(svc, zap.NewNop())
+
+	r := newTestRouter()
+	r.POST("/transactions/:id/authorize", h.Authorize)
+	r.POST("/transactions/:id/capture", h.Capture)
+	r.POST("/transactions/:id/void", h.Void)
+
+	return r
+}
+
+func TestAuthorizationHandler_Flow(t *testing.T) {
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+
+	tx := domain.NewTransaction(1, idr(10000))
+	tx.ID = 1
+	txRepo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			if id != 1 {
+				return nil, domain.ErrTransactionNotFound
+			}
+			return tx, nil
+		},
+		updateFn: func(*domain.Transaction) error { return nil },
+	}
+	wallets := &mockWalletRepo{wallets: []domain.Wallet{{ID: 1, UserID: 1, Balance: idr(10000)}}}
+	r := setupAuthorizationRouter(txRepo, wallets)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Capture Before Authorize", func(t *testing.T) {
+		w := post("/transactions/1/capture", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Authorize", func(t *testing.T) {
+		w := post("/transactions/1/authorize", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.StatusAuthorized, tx.Status)
+		assert.Equal(t, idr(0), wallets.wallets[0].Balance)
+	})
+
+	t.Run("Capture Exceeds Authorized", func(t *testing.T) {
+		w := post("/transactions/1/capture", `{"amount":"100.01"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("Partial Capture", func(t *testing.T) {
+		w := post("/transactions/1/capture", `{"amount":"75"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.StatusCaptured, tx.Status)
+		assert.Equal(t, idr(2500), wallets.wallets[0].Balance)
+	})
+
+	t.Run("Void After Capture", func(t *testing.T) {
+		w := post("/transactions/1/void", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		w := post("/transactions/2/authorize", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAuthorizationHandler_Authorize_InsufficientFunds(t *testing.T) {
+	tx := domain.NewTransaction(1, domain.Money{Minor: 10000, Currency: domain.CurrencyIDR})
+	tx.ID = 1
+	txRepo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) { return tx, nil },
+		updateFn:   func(*domain.Transaction) error { return nil },
+	}
+	r := setupAuthorizationRouter(txRepo, &mockWalletRepo{})
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/1/authorize", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"transaction-technical-test/internal/domain"
 	"transaction-technical-test/internal/service"
 )
 
@@ -21,24 +24,336 @@ func NewDashboardHandler(s *service.DashboardService, logger *zap.Logger) *Dashb
 	}
 }
 
+// Summary menerima ?tz= (nama IANA, misal Asia/Jakarta) untuk menentukan
+// batas "hari ini"; tanpa tz dipakai zona waktu bisnis default.
+// ?window=today|7d|custom memilih periode status breakdown; custom memakai
+// ?from=&to= seperti Timeseries dan otomatis dipilih jika from/to diisi.
 func (h *DashboardHandler) Summary(c *gin.Context) {
-	summary, err := h.service.GetSummary()
-	if err != nil {
-		h.logger.Error("failed to get dashboard summary",
-			zap.Error(err),
-		)
+	loc, ok := h.location(c)
+	if !ok {
+		return
+	}
+
+	query, ok := h.window(c, loc)
+	if !ok {
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+	summary, err := h.service.GetSummary(c.Request.Context(), query)
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to get dashboard summary", zap.Error(err))
+		}
+		_ = c.Error(err)
 		return
 	}
 
-	h.logger.Info("dashboard summary retrieved")
+	requestLogger(c, h.logger).Info("dashboard summary retrieved",
+		zap.String("timezone", summary.Timezone),
+		zap.String("window", string(summary.StatusBreakdown.Window)),
+	)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": summary,
 	})
 }
+
+// Timeseries menerima ?from=&to= (RFC3339 atau YYYY-MM-DD, to inklusif
+// untuk tanggal saja), ?interval=hour|day|week|month (default day),
+// ?status= dan ?tz= seperti Summary.
+func (h *DashboardHandler) Timeseries(c *gin.Context) {
+	loc, ok := h.location(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := h.timeRange(c, loc)
+	if !ok {
+		return
+	}
+
+	interval, err := domain.ParseInterval(c.DefaultQuery("interval", string(domain.IntervalDay)))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	status, ok := h.status(c)
+	if !ok {
+		return
+	}
+
+	query := service.TimeseriesQuery{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Status:   status,
+		Location: loc,
+	}
+
+	series, err := h.service.GetTimeseries(c.Request.Context(), query)
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to get dashboard timeseries", zap.Error(err))
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("dashboard timeseries retrieved",
+		zap.String("interval", string(interval)),
+		zap.Int("buckets", len(series.Buckets)),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": series,
+	})
+}
+
+// Distribution menerima ?from=&to=, ?tz= dan ?status= seperti Timeseries,
+// ?currency= (default IDR) dan ?bucket_size= dalam desimal (misal 50000.00)
+// untuk lebar bucket histogram.
+func (h *DashboardHandler) Distribution(c *gin.Context) {
+	loc, ok := h.location(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := h.timeRange(c, loc)
+	if !ok {
+		return
+	}
+
+	currency := domain.Currency(c.DefaultQuery("currency", string(domain.DefaultCurrency)))
+	if !currency.IsValid() {
+		_ = c.Error(domain.ErrInvalidCurrency)
+		return
+	}
+
+	status, ok := h.status(c)
+	if !ok {
+		return
+	}
+
+	query := service.DistributionQuery{
+		Filter: domain.AmountFilter{
+			From:     from,
+			To:       to,
+			Status:   status,
+			Currency: currency,
+		},
+	}
+
+	if bucketSize := c.Query("bucket_size"); bucketSize != "" {
+		size, err := domain.ParseMoney(bucketSize, currency)
+		if err != nil || !size.IsPositive() {
+			requestLogger(c, h.logger).Warn("invalid bucket_size query", zap.String("bucket_size", bucketSize))
+			invalidParameter(c, "invalid bucket_size")
+			return
+		}
+		query.BucketSize = size.Minor
+	}
+
+	distribution, err := h.service.GetDistribution(c.Request.Context(), query)
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to get amount distribution", zap.Error(err))
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("amount distribution retrieved",
+		zap.String("currency", string(currency)),
+		zap.Int64("count", distribution.Count),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": distribution,
+	})
+}
+
+const (
+	defaultTopUsersLimit = 10
+	maxTopUsersLimit     = 100
+)
+
+// TopUsers menerima ?by=volume|count (default volume), ?limit= (default 10,
+// maksimal 100), ?currency= (default IDR) serta ?window=, ?from=&to= dan
+// ?tz= seperti Summary dengan window default 7d.
+func (h *DashboardHandler) TopUsers(c *gin.Context) {
+	loc, ok := h.location(c)
+	if !ok {
+		return
+	}
+
+	window, ok := h.window(c, loc)
+	if !ok {
+		return
+	}
+
+	query := service.TopUsersQuery{
+		Window:   window,
+		Currency: domain.Currency(c.DefaultQuery("currency", string(domain.DefaultCurrency))),
+		Limit:    defaultTopUsersLimit,
+	}
+
+	if !query.Currency.IsValid() {
+		_ = c.Error(domain.ErrInvalidCurrency)
+		return
+	}
+
+	by, err := domain.ParseRankBy(c.DefaultQuery("by", string(domain.RankByVolume)))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	query.By = by
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxTopUsersLimit {
+			requestLogger(c, h.logger).Warn("invalid limit query", zap.String("limit", limit))
+			invalidParameter(c, "limit must be between 1 and "+strconv.Itoa(maxTopUsersLimit))
+			return
+		}
+		query.Limit = n
+	}
+
+	top, err := h.service.GetTopUsers(c.Request.Context(), query)
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to get top users", zap.Error(err))
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("top users retrieved",
+		zap.String("by", string(by)),
+		zap.Int("count", len(top.Users)),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": top,
+	})
+}
+
+// UserStats mengembalikan statistik lifetime transaksi satu user
+func (h *DashboardHandler) UserStats(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid user id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	stats, err := h.service.GetUserStats(c.Request.Context(), uint(id))
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to get user stats",
+				zap.Uint("user_id", uint(id)),
+				zap.Error(err),
+			)
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("user stats retrieved",
+		zap.Uint("user_id", uint(id)),
+		zap.Int64("transaction_count", stats.TransactionCount),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stats,
+	})
+}
+
+// location membaca ?tz= (nama IANA), default zona waktu bisnis.
+// false jika response 400 sudah dikirim.
+func (h *DashboardHandler) location(c *gin.Context) (*time.Location, bool) {
+	tz := c.Query("tz")
+	if tz == "" {
+		return h.service.Location(), true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid tz query", zap.String("tz", tz))
+		invalidParameter(c, "invalid tz")
+		return nil, false
+	}
+	return loc, true
+}
+
+// status membaca ?status=, nil jika kosong. false jika response 400
+// dengan field error sudah dikirim.
+func (h *DashboardHandler) status(c *gin.Context) (*domain.TransactionStatus, bool) {
+	value := c.Query("status")
+	if value == "" {
+		return nil, true
+	}
+
+	status, err := domain.ParseStatus(value)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid status query", zap.String("status", value))
+		validationFailed(c, statusFieldError("status"))
+		return nil, false
+	}
+	return &status, true
+}
+
+// window membaca ?window=today|7d|custom; custom memakai ?from=&to= dan
+// otomatis dipilih jika from/to diisi. Window kosong berarti default service.
+// false jika response 400 sudah dikirim.
+func (h *DashboardHandler) window(c *gin.Context, loc *time.Location) (service.SummaryQuery, bool) {
+	query := service.SummaryQuery{Location: loc}
+
+	if window := c.Query("window"); window != "" {
+		w, err := service.ParseSummaryWindow(window)
+		if err != nil {
+			_ = c.Error(err)
+			return query, false
+		}
+		query.Window = w
+	}
+
+	if query.Window == service.WindowCustom || c.Query("from") != "" || c.Query("to") != "" {
+		from, to, ok := h.timeRange(c, loc)
+		if !ok {
+			return query, false
+		}
+		query.Window = service.WindowCustom
+		query.From, query.To = from, to
+	}
+
+	return query, true
+}
+
+// timeRange membaca ?from=&to= yang wajib diisi keduanya. Tanggal saja
+// dibaca di zona waktu loc. false jika response 400 sudah dikirim.
+func (h *DashboardHandler) timeRange(c *gin.Context, loc *time.Location) (time.Time, time.Time, bool) {
+	fromQuery, toQuery := c.Query("from"), c.Query("to")
+	if fromQuery == "" || toQuery == "" {
+		invalidParameter(c, "from and to are required")
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, _, err := parseTimeQuery(fromQuery, loc, false)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid from query", zap.String("from", fromQuery))
+		invalidParameter(c, "invalid from")
+		return time.Time{}, time.Time{}, false
+	}
+
+	to, _, err := parseTimeQuery(toQuery, loc, true)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid to query", zap.String("to", toQuery))
+		invalidParameter(c, "invalid to")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
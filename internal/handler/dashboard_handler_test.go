@@ -1,53 +1,105 @@
 package handler_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/gin-gonic/gin"
 
+	"transaction-technical-test/internal/apierror"
 	"transaction-technical-test/internal/domain"
 	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/logging"
+	"transaction-technical-test/internal/middleware"
 	"transaction-technical-test/internal/service"
 )
 
 // Mock Error
 type mockDashboardErrorRepo struct{}
 
-func (m *mockDashboardErrorRepo) TotalSuccessToday() (float64, error) {
-	return 0, errors.New("db error")
+func (m *mockDashboardErrorRepo) TotalSuccessToday(context.Context, *time.Location) ([]domain.Money, error) {
+	return nil, errors.New("db error")
 }
-func (m *mockDashboardErrorRepo) AverageAmountPerUser() (float64, error) {
-	return 0, nil
+func (m *mockDashboardErrorRepo) AverageTransactionAmount(context.Context) ([]domain.Money, error) {
+	return nil, nil
 }
-func (m *mockDashboardErrorRepo) Latest(limit int) ([]domain.Transaction, error) {
+func (m *mockDashboardErrorRepo) Latest(ctx context.Context, limit int) ([]domain.Transaction, error) {
 	return nil, nil
 }
 
-func (m *mockDashboardErrorRepo) Create(*domain.Transaction) error { return nil }
-func (m *mockDashboardErrorRepo) FindByID(uint) (*domain.Transaction, error) {
+func (m *mockDashboardErrorRepo) Create(context.Context, *domain.Transaction) error { return nil }
+func (m *mockDashboardErrorRepo) FindByID(context.Context, uint) (*domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockDashboardErrorRepo) FindByIDForUpdate(context.Context, uint) (*domain.Transaction, error) {
 	return nil, nil
 }
-func (m *mockDashboardErrorRepo) FindAll(domain.TransactionFilter) ([]domain.Transaction, error) {
+func (m *mockDashboardErrorRepo) FindAll(context.Context, domain.TransactionFilter) ([]domain.Transaction, error) {
 	return nil, nil
 }
-func (m *mockDashboardErrorRepo) Update(*domain.Transaction) error { return nil }
-func (m *mockDashboardErrorRepo) Delete(uint) error                { return nil }
+func (m *mockDashboardErrorRepo) Count(context.Context, domain.TransactionFilter) (int64, error) {
+	return 0, nil
+}
+func (m *mockDashboardErrorRepo) Update(context.Context, *domain.Transaction) error { return nil }
+func (m *mockDashboardErrorRepo) Delete(context.Context, uint) error                { return nil }
+func (m *mockDashboardErrorRepo) FindExpiredAuthorizations(context.Context, time.Time, int) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockDashboardErrorRepo) History(context.Context, uint) ([]domain.TransactionEvent, error) {
+	return nil, nil
+}
+func (m *mockDashboardErrorRepo) Restore(context.Context, uint) error { return nil }
+func (m *mockDashboardErrorRepo) PurgeDeleted(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *mockDashboardErrorRepo) VolumeByBucket(context.Context, []time.Time, *domain.TransactionStatus) ([]domain.BucketVolume, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) StatusVolumeByBucket(context.Context, []time.Time) ([]domain.BucketStatusVolume, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) AmountSummary(context.Context, domain.AmountFilter) (domain.AmountSummary, error) {
+	return domain.AmountSummary{}, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) AmountsAtRanks(context.Context, domain.AmountFilter, []int64) ([]domain.Money, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) AmountHistogram(context.Context, domain.AmountFilter, []int64) ([]domain.HistogramCount, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) TopUsers(context.Context, domain.TopUsersFilter) ([]domain.UserVolume, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) AverageTotalPerUser(context.Context) ([]domain.Money, error) {
+	return nil, nil
+}
+func (m *mockDashboardErrorRepo) UserVolumeByStatus(context.Context, uint) ([]domain.StatusVolume, error) {
+	return nil, errors.New("db error")
+}
+func (m *mockDashboardErrorRepo) UserActivity(context.Context, uint) (*time.Time, *time.Time, error) {
+	return nil, nil, nil
+}
 
 func TestDashboardHandler_Summary_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := &mockDashboardErrorRepo{}
-	svc := service.NewDashboardService(repo)
+	svc := service.NewDashboardService(repo, time.UTC)
 
 	logger := zap.NewNop()
 	h := handler.NewDashboardHandler(svc, logger)
 
-	r := gin.New()
+	r := newTestRouter()
 	r.GET("/dashboard/summary", h.Summary)
 
 	req := httptest.NewRequest(http.MethodGet, "/dashboard/summary", nil)
@@ -59,39 +111,148 @@ func TestDashboardHandler_Summary_Error(t *testing.T) {
 	}
 }
 
+func TestDashboardHandler_Summary_LogsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardErrorRepo{}, time.UTC), logger)
+
+	r := gin.New()
+	r.Use(middleware.RequestID(logger), middleware.Errors(logger))
+	r.GET("/dashboard/summary", h.Summary)
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/summary", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-dashboard")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(middleware.RequestIDHeader); got != "req-dashboard" {
+		t.Fatalf("expected request ID echoed, got %q", got)
+	}
+
+	var problem middleware.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if problem.CorrelationID != "req-dashboard" {
+		t.Fatalf("expected correlation_id req-dashboard, got %q", problem.CorrelationID)
+	}
+
+	// log handler dan log middleware Errors sama-sama membawa request_id
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if got := e.ContextMap()[logging.RequestIDField]; got != "req-dashboard" {
+			t.Fatalf("expected request_id on %q, got %v", e.Message, got)
+		}
+	}
+}
+
 // Mock Succes
 type mockDashboardSuccessRepo struct{}
 
-func (m *mockDashboardSuccessRepo) TotalSuccessToday() (float64, error) {
-	return 1000, nil
+func (m *mockDashboardSuccessRepo) TotalSuccessToday(context.Context, *time.Location) ([]domain.Money, error) {
+	return []domain.Money{{Minor: 100000, Currency: domain.CurrencyIDR}}, nil
 }
-func (m *mockDashboardSuccessRepo) AverageAmountPerUser() (float64, error) {
-	return 500, nil
+func (m *mockDashboardSuccessRepo) AverageTransactionAmount(context.Context) ([]domain.Money, error) {
+	return []domain.Money{{Minor: 50000, Currency: domain.CurrencyIDR}}, nil
 }
-func (m *mockDashboardSuccessRepo) Latest(limit int) ([]domain.Transaction, error) {
+func (m *mockDashboardSuccessRepo) Latest(ctx context.Context, limit int) ([]domain.Transaction, error) {
 	return []domain.Transaction{}, nil
 }
 
-func (m *mockDashboardSuccessRepo) Create(*domain.Transaction) error { return nil }
-func (m *mockDashboardSuccessRepo) FindByID(uint) (*domain.Transaction, error) {
+func (m *mockDashboardSuccessRepo) Create(context.Context, *domain.Transaction) error { return nil }
+func (m *mockDashboardSuccessRepo) FindByID(context.Context, uint) (*domain.Transaction, error) {
 	return nil, nil
 }
-func (m *mockDashboardSuccessRepo) FindAll(domain.TransactionFilter) ([]domain.Transaction, error) {
+func (m *mockDashboardSuccessRepo) FindByIDForUpdate(context.Context, uint) (*domain.Transaction, error) {
 	return nil, nil
 }
-func (m *mockDashboardSuccessRepo) Update(*domain.Transaction) error { return nil }
-func (m *mockDashboardSuccessRepo) Delete(uint) error                { return nil }
+func (m *mockDashboardSuccessRepo) FindAll(context.Context, domain.TransactionFilter) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockDashboardSuccessRepo) Count(context.Context, domain.TransactionFilter) (int64, error) {
+	return 0, nil
+}
+func (m *mockDashboardSuccessRepo) Update(context.Context, *domain.Transaction) error { return nil }
+func (m *mockDashboardSuccessRepo) Delete(context.Context, uint) error                { return nil }
+func (m *mockDashboardSuccessRepo) FindExpiredAuthorizations(context.Context, time.Time, int) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockDashboardSuccessRepo) History(context.Context, uint) ([]domain.TransactionEvent, error) {
+	return nil, nil
+}
+func (m *mockDashboardSuccessRepo) Restore(context.Context, uint) error { return nil }
+func (m *mockDashboardSuccessRepo) PurgeDeleted(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *mockDashboardSuccessRepo) VolumeByBucket(context.Context, []time.Time, *domain.TransactionStatus) ([]domain.BucketVolume, error) {
+	return []domain.BucketVolume{{Bucket: 1, Count: 2, Sum: domain.Money{Minor: 30000, Currency: domain.CurrencyIDR}}}, nil
+}
+func (m *mockDashboardSuccessRepo) StatusVolumeByBucket(context.Context, []time.Time) ([]domain.BucketStatusVolume, error) {
+	idr := domain.Money{Minor: 10000, Currency: domain.CurrencyIDR}
+	return []domain.BucketStatusVolume{
+		{Bucket: 0, StatusVolume: domain.StatusVolume{Status: domain.StatusSuccess, Count: 1, Sum: idr, Net: idr, Largest: idr}},
+		{Bucket: 1, StatusVolume: domain.StatusVolume{Status: domain.StatusFailed, Count: 1, Sum: idr, Net: idr, Largest: idr}},
+		{Bucket: 1, StatusVolume: domain.StatusVolume{Status: domain.StatusSuccess, Count: 3, Sum: idr, Net: idr, Largest: idr}},
+	}, nil
+}
+func (m *mockDashboardSuccessRepo) AmountSummary(_ context.Context, f domain.AmountFilter) (domain.AmountSummary, error) {
+	return domain.AmountSummary{
+		Count: 4,
+		Min:   domain.Money{Minor: 1000, Currency: f.Currency},
+		Max:   domain.Money{Minor: 9000, Currency: f.Currency},
+	}, nil
+}
+func (m *mockDashboardSuccessRepo) AmountsAtRanks(_ context.Context, f domain.AmountFilter, ranks []int64) ([]domain.Money, error) {
+	amounts := make([]domain.Money, 0, len(ranks))
+	for _, rank := range ranks {
+		amounts = append(amounts, domain.Money{Minor: rank * 1000, Currency: f.Currency})
+	}
+	return amounts, nil
+}
+func (m *mockDashboardSuccessRepo) AmountHistogram(context.Context, domain.AmountFilter, []int64) ([]domain.HistogramCount, error) {
+	return []domain.HistogramCount{{Bucket: 0, Count: 3}, {Bucket: 1, Count: 1}}, nil
+}
+func (m *mockDashboardSuccessRepo) TopUsers(_ context.Context, f domain.TopUsersFilter) ([]domain.UserVolume, error) {
+	users := []domain.UserVolume{
+		{UserID: 3, Count: 2, Volume: domain.Money{Minor: 20000, Currency: f.Currency}},
+		{UserID: 1, Count: 1, Volume: domain.Money{Minor: 10000, Currency: f.Currency}},
+	}
+	if f.Limit < len(users) {
+		users = users[:f.Limit]
+	}
+	return users, nil
+}
+func (m *mockDashboardSuccessRepo) AverageTotalPerUser(context.Context) ([]domain.Money, error) {
+	return []domain.Money{{Minor: 150000, Currency: domain.CurrencyIDR}}, nil
+}
+func (m *mockDashboardSuccessRepo) UserVolumeByStatus(context.Context, uint) ([]domain.StatusVolume, error) {
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	return []domain.StatusVolume{
+		{Status: domain.StatusFailed, Count: 1, Sum: idr(5000), Net: idr(5000), Largest: idr(5000)},
+		{Status: domain.StatusSuccess, Count: 3, Sum: idr(60000), Net: idr(60000), Largest: idr(30000)},
+	}, nil
+}
+func (m *mockDashboardSuccessRepo) UserActivity(context.Context, uint) (*time.Time, *time.Time, error) {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	return &first, &last, nil
+}
 
 func TestDashboardHandler_Summary_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := &mockDashboardSuccessRepo{}
-	svc := service.NewDashboardService(repo)
+	svc := service.NewDashboardService(repo, time.UTC)
 
 	logger := zap.NewNop()
 	h := handler.NewDashboardHandler(svc, logger)
 
-	r := gin.New()
+	r := newTestRouter()
 	r.GET("/dashboard/summary", h.Summary)
 
 	req := httptest.NewRequest(http.MethodGet, "/dashboard/summary", nil)
@@ -102,3 +263,406 @@ func TestDashboardHandler_Summary_Success(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestDashboardHandler_Summary_Timezone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/summary", h.Summary)
+
+	t.Run("Valid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/summary?tz=Asia/Jakarta", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"timezone":"Asia/Jakarta"`) {
+			t.Fatalf("expected timezone in response, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/summary?tz=Mars/Olympus", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestDashboardHandler_Summary_Window(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/summary", h.Summary)
+
+	t.Run("Last 7 Days", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/summary?window=7d", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+
+		var body struct {
+			Data service.DashboardSummary `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		breakdown := body.Data.StatusBreakdown
+		if breakdown.Window != service.WindowLast7Days {
+			t.Fatalf("expected 7d window, got %q", breakdown.Window)
+		}
+		if breakdown.Current.Count != 4 || breakdown.Previous.Count != 1 || breakdown.Delta.Count != 3 {
+			t.Fatalf("unexpected breakdown: %+v", breakdown)
+		}
+	})
+
+	t.Run("Custom Range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/summary?from=2024-01-01&to=2024-01-07", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"window":"custom"`) {
+			t.Fatalf("expected custom window, got %s", w.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Unknown Window", query: "window=30d"},
+		{name: "Custom Without Range", query: "window=custom"},
+		{name: "Inverted Range", query: "from=2024-01-07T00:00:00Z&to=2024-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/dashboard/summary?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestDashboardHandler_Timeseries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/timeseries", h.Timeseries)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/timeseries?from=2024-01-01&to=2024-01-03&interval=day", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Data service.Timeseries `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		// to tanggal saja inklusif: 1, 2 dan 3 Januari
+		if len(body.Data.Buckets) != 3 {
+			t.Fatalf("expected 3 buckets, got %d", len(body.Data.Buckets))
+		}
+		if body.Data.Buckets[0].Count != 0 || body.Data.Buckets[1].Count != 2 {
+			t.Fatalf("unexpected bucket counts: %+v", body.Data.Buckets)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Missing Range", query: "interval=day"},
+		{name: "Invalid From", query: "from=yesterday&to=2024-01-03"},
+		{name: "Invalid Interval", query: "from=2024-01-01&to=2024-01-03&interval=minute"},
+		{name: "Inverted Range", query: "from=2024-01-03T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{name: "Too Many Buckets", query: "from=2000-01-01&to=2024-01-01&interval=hour"},
+		{name: "Invalid Timezone", query: "from=2024-01-01&to=2024-01-03&tz=Mars/Olympus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/dashboard/timeseries?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestDashboardHandler_Timeseries_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardErrorRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/timeseries", h.Timeseries)
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/timeseries?from=2024-01-01&to=2024-01-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestDashboardHandler_UserStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := newTestRouter()
+	r.GET("/users/:id/stats", handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop()).UserStats)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/1/stats", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+
+		var body struct {
+			Data service.UserStats `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		if body.Data.TransactionCount != 4 || body.Data.SuccessRate != 0.75 {
+			t.Fatalf("unexpected stats: %+v", body.Data)
+		}
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/abc/stats", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("Repository Error", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/users/:id/stats", handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardErrorRepo{}, time.UTC), zap.NewNop()).UserStats)
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1/stats", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestDashboardHandler_Distribution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/distribution", h.Distribution)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/distribution?from=2024-01-01&to=2024-01-31&bucket_size=50.00", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Data struct {
+				Currency    domain.Currency `json:"currency"`
+				BucketSize  moneyBody       `json:"bucket_size"`
+				Percentiles struct {
+					P50 moneyBody `json:"p50"`
+					P99 moneyBody `json:"p99"`
+				} `json:"percentiles"`
+				Histogram []struct {
+					Count int64 `json:"count"`
+				} `json:"histogram"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		if body.Data.Currency != domain.CurrencyIDR || body.Data.BucketSize.Amount != "50.00" {
+			t.Fatalf("unexpected distribution: %+v", body.Data)
+		}
+		// 0-5000 dan 5000-10000
+		if len(body.Data.Histogram) != 2 || body.Data.Histogram[0].Count != 3 {
+			t.Fatalf("unexpected histogram: %+v", body.Data.Histogram)
+		}
+		if body.Data.Percentiles.P50.Amount != "20.00" || body.Data.Percentiles.P99.Amount != "40.00" {
+			t.Fatalf("unexpected percentiles: %+v", body.Data.Percentiles)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Missing Range", query: "currency=IDR"},
+		{name: "Unknown Currency", query: "from=2024-01-01&to=2024-01-31&currency=XXX"},
+		{name: "Zero Bucket Size", query: "from=2024-01-01&to=2024-01-31&bucket_size=0"},
+		{name: "Too Precise Bucket Size", query: "from=2024-01-01&to=2024-01-31&bucket_size=0.001"},
+		{name: "Too Many Buckets", query: "from=2024-01-01&to=2024-01-31&bucket_size=0.01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/dashboard/distribution?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+
+	t.Run("Repository Error", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/dashboard/distribution", handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardErrorRepo{}, time.UTC), zap.NewNop()).Distribution)
+
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/distribution?from=2024-01-01&to=2024-01-31", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestDashboardHandler_StatusQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/timeseries", h.Timeseries)
+	r.GET("/dashboard/distribution", h.Distribution)
+
+	for _, path := range []string{"/dashboard/timeseries", "/dashboard/distribution"} {
+		t.Run(path+" Unknown Status", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path+"?from=2024-01-01&to=2024-01-03&status=bogus", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+
+			var problem middleware.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if problem.Code != apierror.CodeValidationFailed || len(problem.Errors) != 1 || problem.Errors[0].Field != "status" {
+				t.Fatalf("expected status field error, got %+v", problem)
+			}
+			if len(problem.Errors[0].Allowed) != len(domain.Statuses) {
+				t.Fatalf("expected allowed statuses, got %+v", problem.Errors[0])
+			}
+		})
+
+		t.Run(path+" Known Status", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path+"?from=2024-01-01&to=2024-01-03&status=success", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDashboardHandler_TopUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDashboardHandler(service.NewDashboardService(&mockDashboardSuccessRepo{}, time.UTC), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/top-users", h.TopUsers)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/top-users?by=count&limit=1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Data service.TopUsers `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		if body.Data.Window != service.WindowLast7Days || body.Data.By != domain.RankByCount {
+			t.Fatalf("unexpected defaults: %+v", body.Data)
+		}
+		if len(body.Data.Users) != 1 || body.Data.Users[0].UserID != 3 || body.Data.Users[0].Rank != 1 {
+			t.Fatalf("unexpected users: %+v", body.Data.Users)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Unknown By", query: "by=amount"},
+		{name: "Zero Limit", query: "limit=0"},
+		{name: "Limit Too Large", query: "limit=1000"},
+		{name: "Unknown Window", query: "window=30d"},
+		{name: "Unknown Currency", query: "currency=XXX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/dashboard/top-users?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package handler berisi HTTP handler gin. Handler tidak menulis response
+// error sendiri: error dicatat dengan c.Error lalu middleware.Errors
+// menulisnya sebagai application/problem+json.
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"transaction-technical-test/internal/apierror"
+	"transaction-technical-test/internal/domain"
+)
+
+// invalidParameter mencatat 400 untuk path param atau query yang tidak valid
+func invalidParameter(c *gin.Context, detail string) {
+	_ = c.Error(apierror.BadRequest(apierror.CodeInvalidParameter, detail))
+}
+
+// isServerError dipakai untuk memilih level log, 5xx dicatat sebagai error
+func isServerError(err error) bool {
+	return apierror.From(err).Status >= 500
+}
+
+// requireAdmin mencatat 403 jika request tidak membawa token admin yang
+// valid (lihat middleware.Audit). false berarti handler harus berhenti.
+func requireAdmin(c *gin.Context) bool {
+	if domain.AuditInfoFromContext(c.Request.Context()).Admin {
+		return true
+	}
+	_ = c.Error(domain.ErrAdminRequired)
+	return false
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// formatETag menulis versi transaksi sebagai strong ETag, misal "3"
+func formatETag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// parseIfMatch mengambil daftar versi dari header If-Match, misal
+// "3", "4". anyVersion true untuk "*" yang berarti versi apa saja. If-Match memakai
+// strong comparison (RFC 7232 3.1): weak ETag (W/"3") valid secara sintaks
+// tapi tidak pernah cocok, jadi tidak masuk ke versions.
+func parseIfMatch(header string) (versions []uint, anyVersion bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true, nil
+	}
+
+	parts := strings.Split(header, ",")
+	versions = make([]uint, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		weak := strings.HasPrefix(part, "W/")
+		unquoted, err := strconv.Unquote(strings.TrimPrefix(part, "W/"))
+		if err != nil {
+			return nil, false, errInvalidIfMatch
+		}
+
+		version, err := strconv.ParseUint(unquoted, 10, 64)
+		if err != nil || version == 0 {
+			return nil, false, errInvalidIfMatch
+		}
+		if !weak {
+			versions = append(versions, uint(version))
+		}
+	}
+
+	return versions, false, nil
+}
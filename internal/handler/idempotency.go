@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/apierror"
+	"transaction-technical-test/internal/domain"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	jsonContentType          = "application/json; charset=utf-8"
+)
+
+// createFingerprint dihitung dari request yang sudah dinormalisasi, sehingga
+// perbedaan format body (spasi, "1000" vs "1000.00") tetap dianggap sama.
+func createFingerprint(userID uint, amount domain.Money) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"POST /api/transactions|%d|%s|%s",
+		userID, amount.String(), amount.Currency,
+	)))
+	return hex.EncodeToString(sum[:])
+}
+
+// beginIdempotent mengembalikan true jika request sudah dibalas (replay atau error)
+func (h *TransactionHandler) beginIdempotent(c *gin.Context, key, fingerprint string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		requestLogger(c, h.logger).Warn("idempotency key too long", zap.Int("length", len(key)))
+		_ = c.Error(apierror.BadRequest(apierror.CodeInvalidHeader, fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)))
+		return true
+	}
+
+	replay, err := h.idempotency.Begin(c.Request.Context(), key, fingerprint)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIdempotencyKeyMismatch):
+			requestLogger(c, h.logger).Warn("idempotency key reused with different request", zap.String("idempotency_key", key))
+		case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+			requestLogger(c, h.logger).Warn("idempotent request still in progress", zap.String("idempotency_key", key))
+		default:
+			requestLogger(c, h.logger).Error("failed to begin idempotent request",
+				zap.String("idempotency_key", key),
+				zap.Error(err),
+			)
+		}
+		_ = c.Error(err)
+		return true
+	}
+
+	if replay != nil {
+		requestLogger(c, h.logger).Info("replaying idempotent response", zap.String("idempotency_key", key))
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(replay.StatusCode, jsonContentType, replay.ResponseBody)
+		return true
+	}
+
+	return false
+}
+
+// completeIdempotent dan releaseIdempotent tetap jalan walau client sudah
+// disconnect, supaya key tidak tertahan "in progress" sampai TTL habis.
+func (h *TransactionHandler) completeIdempotent(c *gin.Context, key string, statusCode int, body []byte) {
+	if key == "" {
+		return
+	}
+	if err := h.idempotency.Complete(context.WithoutCancel(c.Request.Context()), key, statusCode, body); err != nil {
+		requestLogger(c, h.logger).Error("failed to store idempotent response",
+			zap.String("idempotency_key", key),
+			zap.Error(err),
+		)
+	}
+}
+
+func (h *TransactionHandler) releaseIdempotent(c *gin.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := h.idempotency.Release(context.WithoutCancel(c.Request.Context()), key); err != nil {
+		requestLogger(c, h.logger).Error("failed to release idempotency key",
+			zap.String("idempotency_key", key),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type mockIdempotencyRepo struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func newMockIdempotencyRepo() *mockIdempotencyRepo {
+	return &mockIdempotencyRepo{records: map[string]domain.IdempotencyRecord{}}
+}
+
+func (m *mockIdempotencyRepo) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[rec.Key]; ok {
+		return false, nil
+	}
+	m.records[rec.Key] = *rec
+	return true, nil
+}
+func (m *mockIdempotencyRepo) FindByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok {
+		return nil, domain.ErrIdempotencyKeyNotFound
+	}
+	return &rec, nil
+}
+func (m *mockIdempotencyRepo) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+	rec.StatusCode = statusCode
+	rec.ResponseBody = body
+	m.records[key] = rec
+	return nil
+}
+func (m *mockIdempotencyRepo) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+func (m *mockIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func postTransaction(t *testing.T, r http.Handler, key, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTransactionHandler_Create_IdempotencyReplay(t *testing.T) {
+	created := 0
+	repo := &mockTransactionRepo{
+		createFn: func(tx *domain.Transaction) error {
+			created++
+			tx.ID = uint(created)
+			return nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	first := postTransaction(t, r, "key-1", `{"user_id":1,"amount":1000}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	// Format berbeda, nilai sama
+	second := postTransaction(t, r, "key-1", `{"user_id":1, "amount":"1000.00", "currency":"IDR"}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, created)
+
+	third := postTransaction(t, r, "key-2", `{"user_id":1,"amount":1000}`)
+	assert.Equal(t, http.StatusCreated, third.Code)
+	assert.Equal(t, 2, created)
+}
+
+func TestTransactionHandler_Create_IdempotencyMismatch(t *testing.T) {
+	repo := &mockTransactionRepo{
+		createFn: func(tx *domain.Transaction) error { return nil },
+	}
+	r := setupTransactionRouter(repo)
+
+	first := postTransaction(t, r, "key-1", `{"user_id":1,"amount":1000}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	second := postTransaction(t, r, "key-1", `{"user_id":1,"amount":2000}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, second.Code)
+}
+
+func TestTransactionHandler_Create_IdempotencyReleasedOnError(t *testing.T) {
+	fail := true
+	repo := &mockTransactionRepo{
+		createFn: func(tx *domain.Transaction) error {
+			if fail {
+				return assert.AnError
+			}
+			return nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	first := postTransaction(t, r, "key-1", `{"user_id":1,"amount":1000}`)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+
+	fail = false
+	second := postTransaction(t, r, "key-1", `{"user_id":1,"amount":1000}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+}
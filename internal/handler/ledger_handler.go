@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/service"
+)
+
+type LedgerHandler struct {
+	service *service.LedgerService
+	logger  *zap.Logger
+}
+
+func NewLedgerHandler(s *service.LedgerService, logger *zap.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		service: s,
+		logger:  logger,
+	}
+}
+
+func (h *LedgerHandler) AccountBalance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid account id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	balance, err := h.service.GetAccountBalance(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			requestLogger(c, h.logger).Info("ledger account not found", zap.Uint("account_id", uint(id)))
+			_ = c.Error(err)
+			return
+		}
+
+		requestLogger(c, h.logger).Error("failed to get account balance",
+			zap.Uint("account_id", uint(id)),
+			zap.Error(err),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("account balance retrieved", zap.Uint("account_id", uint(id)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": balance,
+	})
+}
+
+// Check menjalankan invariant checker, 500 ledger_imbalanced jika total
+// posting tidak nol. Total per mata uang hanya dicatat di log.
+func (h *LedgerHandler) Check(c *gin.Context) {
+	check, err := h.service.CheckInvariant(c.Request.Context())
+	if err != nil {
+		requestLogger(c, h.logger).Error("failed to check ledger invariant", zap.Error(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if !check.Balanced {
+		requestLogger(c, h.logger).Error("ledger invariant violated", zap.Any("totals", check.Totals))
+		_ = c.Error(domain.ErrLedgerImbalanced)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("ledger invariant checked")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": check,
+	})
+}
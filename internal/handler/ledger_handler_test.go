@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/service"
+)
+
+type mockLedgerRepo struct {
+	accounts []domain.Account
+	entries  []domain.JournalEntry
+}
+
+func (m *mockLedgerRepo) FindOrCreateAccount(ctx context.Context, code string, accountType domain.AccountType, currency domain.Currency) (*domain.Account, error) {
+	for i := range m.accounts {
+		if m.accounts[i].Code == code {
+			return &m.accounts[i], nil
+		}
+	}
+	m.accounts = append(m.accounts, domain.Account{
+		ID:       uint(len(m.accounts) + 1),
+		Code:     code,
+		Type:     accountType,
+		Currency: currency,
+	})
+	return &m.accounts[len(m.accounts)-1], nil
+}
+func (m *mockLedgerRepo) FindAccountByID(ctx context.Context, id uint) (*domain.Account, error) {
+	for i := range m.accounts {
+		if m.accounts[i].ID == id {
+			return &m.accounts[i], nil
+		}
+	}
+	return nil, domain.ErrAccountNotFound
+}
+func (m *mockLedgerRepo) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	entry.ID = uint(len(m.entries) + 1)
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+func (m *mockLedgerRepo) AccountBalance(ctx context.Context, accountID uint) (int64, error) {
+	var balance int64
+	for _, e := range m.entries {
+		for _, p := range e.Postings {
+			if p.AccountID == accountID {
+				balance += p.Amount.Minor
+			}
+		}
+	}
+	return balance, nil
+}
+func (m *mockLedgerRepo) PostingTotals(ctx context.Context) ([]domain.Money, error) {
+	totals := map[domain.Currency]int64{}
+	for _, e := range m.entries {
+		for _, p := range e.Postings {
+			totals[p.Amount.Currency] += p.Amount.Minor
+		}
+	}
+
+	result := make([]domain.Money, 0, len(totals))
+	for currency, total := range totals {
+		result = append(result, domain.Money{Minor: total, Currency: currency})
+	}
+	return result, nil
+}
+
+func setupLedgerRouter(repo *mockLedgerRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewLedgerHandler(service.NewLedgerService(repo), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/ledger/accounts/:id/balance", h.AccountBalance)
+	r.GET("/ledger/check", h.Check)
+
+	return r
+}
+
+func TestLedgerHandler_AccountBalance(t *testing.T) {
+	repo := &mockLedgerRepo{}
+	svc := service.NewLedgerService(repo)
+	tx := &domain.Transaction{ID: 1, UserID: 3, Amount: domain.Money{Minor: 150050, Currency: domain.CurrencyIDR}}
+	assert.NoError(t, svc.RecordPayment(context.Background(), tx))
+
+	r := setupLedgerRouter(repo)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/accounts/1/balance", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Data struct {
+				Balance moneyBody `json:"balance"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "1500.50", body.Data.Balance.Amount)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/accounts/99/balance", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ledger/accounts/abc/balance", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestLedgerHandler_Check(t *testing.T) {
+	t.Run("Balanced", func(t *testing.T) {
+		r := setupLedgerRouter(&mockLedgerRepo{})
+
+		req := httptest.NewRequest(http.MethodGet, "/ledger/check", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Imbalanced", func(t *testing.T) {
+		repo := &mockLedgerRepo{
+			entries: []domain.JournalEntry{{
+				Postings: []domain.Posting{{AccountID: 1, Amount: domain.Money{Minor: 10, Currency: domain.CurrencyIDR}}},
+			}},
+		}
+		r := setupLedgerRouter(repo)
+
+		req := httptest.NewRequest(http.MethodGet, "/ledger/check", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"code":"ledger_imbalanced"`)
+	})
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/logging"
+)
+
+// requestLogger adalah logger dengan request_id dari middleware.RequestID;
+// fallback (logger milik handler) dipakai jika middleware tidak dipasang
+func requestLogger(c *gin.Context, fallback *zap.Logger) *zap.Logger {
+	return logging.FromContext(c.Request.Context(), fallback)
+}
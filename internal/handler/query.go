@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateLayout adalah format tanggal tanpa jam pada query string
+const dateLayout = "2006-01-02"
+
+// parseTimeQuery menerima RFC3339 atau tanggal saja (YYYY-MM-DD) yang
+// dibaca di zona waktu loc. Jika endOfDay true, tanggal saja diartikan
+// inklusif sehingga yang dikembalikan adalah awal hari berikutnya; dateOnly
+// memberi tahu pemanggil bahwa batas itu harus dipakai eksklusif.
+func parseTimeQuery(value string, loc *time.Location, endOfDay bool) (t time.Time, dateOnly bool, err error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, false, nil
+	}
+
+	t, err = time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, true, nil
+}
+
+// queryValues mengambil semua nilai key, baik diulang (?status=a&status=b)
+// maupun dipisah koma (?status=a,b). Nilai kosong dibuang.
+func queryValues(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/service"
+)
+
+type RefundHandler struct {
+	service *service.RefundService
+	logger  *zap.Logger
+}
+
+func NewRefundHandler(s *service.RefundService, logger *zap.Logger) *RefundHandler {
+	return &RefundHandler{
+		service: s,
+		logger:  logger,
+	}
+}
+
+// CreateRefundRequest: amount kosong berarti refund seluruh sisa amount
+type CreateRefundRequest struct {
+	Amount   json.Number     `json:"amount"`
+	Currency domain.Currency `json:"currency"`
+	Reason   string          `json:"reason" binding:"max=255"`
+}
+
+func (h *RefundHandler) Create(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	var req CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c, h.logger).Warn("invalid create refund request",
+			zap.Uint("transaction_id", uint(id)),
+			zap.Error(err),
+		)
+		bindingFailed(c, err)
+		return
+	}
+
+	refund, err := h.service.Create(c.Request.Context(), uint(id), req.Amount.String(), req.Currency, req.Reason)
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to create refund",
+				zap.Uint("transaction_id", uint(id)),
+				zap.String("amount", req.Amount.String()),
+				zap.Error(err),
+			)
+		} else {
+			requestLogger(c, h.logger).Warn("refund rejected",
+				zap.Uint("transaction_id", uint(id)),
+				zap.String("amount", req.Amount.String()),
+				zap.Error(err),
+			)
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("refund created",
+		zap.Uint("refund_id", refund.ID),
+		zap.Uint("transaction_id", refund.TransactionID),
+		zap.String("amount", refund.Amount.String()),
+		zap.String("currency", string(refund.Amount.Currency)),
+	)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": refund,
+	})
+}
+
+func (h *RefundHandler) GetByTransactionID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	refunds, err := h.service.GetByTransactionID(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionNotFound) {
+			requestLogger(c, h.logger).Info("transaction not found", zap.Uint("transaction_id", uint(id)))
+			_ = c.Error(err)
+			return
+		}
+
+		requestLogger(c, h.logger).Error("failed to get refunds",
+			zap.Uint("transaction_id", uint(id)),
+			zap.Error(err),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("refunds retrieved",
+		zap.Uint("transaction_id", uint(id)),
+		zap.Int("count", len(refunds)),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": refunds,
+	})
+}
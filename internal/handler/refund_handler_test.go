@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/service"
+)
+
+type mockRefundRepo struct {
+	refunds []domain.Refund
+}
+
+func (m *mockRefundRepo) Create(ctx context.Context, refund *domain.Refund) error {
+	refund.ID = uint(len(m.refunds) + 1)
+	m.refunds = append(m.refunds, *refund)
+	return nil
+}
+func (m *mockRefundRepo) FindByTransactionID(ctx context.Context, transactionID uint) ([]domain.Refund, error) {
+	return m.refunds, nil
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func setupRefundRouter(txRepo *mockTransactionRepo, refundRepo *mockRefundRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	svc := service.NewRefundService(mockTransactor{}, txRepo, refundRepo, service.NewLedgerService(&mockLedgerRepo{}), service.NewWalletService(&mockWalletRepo{}), service.NewBroadcaster(1, zap.NewNop()))
+	h := handler.NewRefundHandler(svc, zap.NewNop())
+
+	r := newTestRouter()
+	r.POST("/transactions/:id/refunds", h.Create)
+	r.GET("/transactions/:id/refunds", h.GetByTransactionID)
+
+	return r
+}
+
+func postRefund(r http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRefundHandler_Create(t *testing.T) {
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+
+	tx := &domain.Transaction{ID: 1, Amount: idr(100000), Refunded: idr(0), Status: domain.StatusSuccess}
+	txRepo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			if id != 1 {
+				return nil, domain.ErrTransactionNotFound
+			}
+			return tx, nil
+		},
+		updateFn: func(*domain.Transaction) error { return nil },
+	}
+	refundRepo := &mockRefundRepo{}
+	r := setupRefundRouter(txRepo, refundRepo)
+
+	t.Run("Partial Refund", func(t *testing.T) {
+		w := postRefund(r, "/transactions/1/refunds", `{"amount":"400","reason":"damaged"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, domain.StatusPartiallyRefunded, tx.Status)
+	})
+
+	t.Run("Exceeds Remaining", func(t *testing.T) {
+		w := postRefund(r, "/transactions/1/refunds", `{"amount":"600.01"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("Invalid Amount", func(t *testing.T) {
+		w := postRefund(r, "/transactions/1/refunds", `{"amount":"1.001"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Remaining Refund", func(t *testing.T) {
+		w := postRefund(r, "/transactions/1/refunds", `{}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, domain.StatusRefunded, tx.Status)
+		assert.Len(t, refundRepo.refunds, 2)
+	})
+
+	t.Run("Already Refunded", func(t *testing.T) {
+		w := postRefund(r, "/transactions/1/refunds", `{}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		w := postRefund(r, "/transactions/99/refunds", `{}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := postRefund(r, "/transactions/abc/refunds", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/1/refunds", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRefundHandler_Create_PendingTransaction(t *testing.T) {
+	txRepo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			return &domain.Transaction{
+				ID:     id,
+				Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR},
+				Status: domain.StatusPending,
+			}, nil
+		},
+	}
+	r := setupRefundRouter(txRepo, &mockRefundRepo{})
+
+	w := postRefund(r, "/transactions/1/refunds", `{"amount":"5"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
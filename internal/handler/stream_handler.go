@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/service"
+)
+
+type StreamHandler struct {
+	feed      *service.Broadcaster
+	heartbeat time.Duration
+	logger    *zap.Logger
+}
+
+func NewStreamHandler(feed *service.Broadcaster, heartbeat time.Duration, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		feed:      feed,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// Stream mengirim transaksi yang dibuat atau berubah status sebagai
+// Server-Sent Events (event "created" / "updated"). Filter opsional
+// ?user_id= dan ?status=. Komentar heartbeat dikirim setiap interval
+// supaya koneksi tidak diputus proxy saat tidak ada transaksi.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	var filter service.FeedFilter
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid user_id query", zap.String("user_id", userID))
+			invalidParameter(c, "invalid user_id")
+			return
+		}
+		uid := uint(id)
+		filter.UserID = &uid
+	}
+
+	if value := c.Query("status"); value != "" {
+		status, err := domain.ParseStatus(value)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid status query", zap.String("status", value))
+			validationFailed(c, statusFieldError("status"))
+			return
+		}
+		filter.Status = &status
+	}
+
+	events, unsubscribe := h.feed.Subscribe(filter)
+	defer unsubscribe()
+
+	requestLogger(c, h.logger).Info("stream client connected", zap.Int("subscribers", h.feed.Subscribers()))
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginx tidak boleh mem-buffer response SSE
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+
+	requestLogger(c, h.logger).Info("stream client disconnected")
+}
@@ -0,0 +1,201 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/service"
+)
+
+func setupStreamServer(t *testing.T, heartbeat time.Duration) (*httptest.Server, *service.Broadcaster) {
+	gin.SetMode(gin.TestMode)
+
+	feed := service.NewBroadcaster(8, zap.NewNop())
+	h := handler.NewStreamHandler(feed, heartbeat, zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/stream", h.Stream)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, feed
+}
+
+// openStream membuka koneksi SSE dan menunggu sampai subscriber terdaftar
+func openStream(t *testing.T, srv *httptest.Server, feed *service.Broadcaster, query string) *bufio.Reader {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/dashboard/stream"+query, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for feed.Subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream client never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readFrame membaca satu frame SSE (sampai baris kosong)
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	var frame strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v", err)
+		}
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+func TestStreamHandler_Events(t *testing.T) {
+	srv, feed := setupStreamServer(t, time.Hour)
+	stream := openStream(t, srv, feed, "?user_id=7&status=success")
+
+	// tidak lolos filter user
+	feed.Publish(service.FeedEvent{
+		Type:        domain.EventTransactionUpdated,
+		Transaction: domain.Transaction{ID: 1, UserID: 8, Status: domain.StatusSuccess},
+	})
+	feed.Publish(service.FeedEvent{
+		Type:        domain.EventTransactionUpdated,
+		Transaction: domain.Transaction{ID: 2, UserID: 7, Status: domain.StatusSuccess},
+		OldStatus:   domain.StatusPending,
+	})
+
+	frame := readFrame(t, stream)
+	if !strings.Contains(frame, "event:updated") {
+		t.Fatalf("expected updated event, got %q", frame)
+	}
+	if !strings.Contains(frame, `"ID":2`) || !strings.Contains(frame, `"old_status":"pending"`) {
+		t.Fatalf("expected transaction 2 in event data, got %q", frame)
+	}
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	srv, feed := setupStreamServer(t, 10*time.Millisecond)
+	stream := openStream(t, srv, feed, "")
+
+	if frame := readFrame(t, stream); frame != ": heartbeat\n" {
+		t.Fatalf("expected heartbeat comment, got %q", frame)
+	}
+}
+
+func TestStreamHandler_Unsubscribes(t *testing.T) {
+	srv, feed := setupStreamServer(t, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/dashboard/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	cancel()
+	resp.Body.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for feed.Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected subscriber to be removed after disconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamHandler_InvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewStreamHandler(service.NewBroadcaster(1, zap.NewNop()), time.Second, zap.NewNop())
+	r := newTestRouter()
+	r.GET("/dashboard/stream", h.Stream)
+
+	for _, query := range []string{"user_id=abc", "status=sucess"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/stream?"+query, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestStreamHandler_CaptureAndRefund(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+
+	tx := domain.NewTransaction(7, idr(10000))
+	tx.ID = 1
+	txRepo := &mockTransactionRepo{
+		findByIDFn: func(uint) (*domain.Transaction, error) { return tx, nil },
+		updateFn:   func(*domain.Transaction) error { return nil },
+	}
+	wallets := &mockWalletRepo{wallets: []domain.Wallet{{ID: 1, UserID: 7, Balance: idr(10000)}}}
+
+	feed := service.NewBroadcaster(8, zap.NewNop())
+	ledger := service.NewLedgerService(&mockLedgerRepo{})
+	walletService := service.NewWalletService(wallets)
+	authorizations := handler.NewAuthorizationHandler(service.NewAuthorizationService(mockTransactor{}, txRepo, ledger, walletService, feed, time.Hour), zap.NewNop())
+	refunds := handler.NewRefundHandler(service.NewRefundService(mockTransactor{}, txRepo, &mockRefundRepo{}, ledger, walletService, feed), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/dashboard/stream", handler.NewStreamHandler(feed, time.Hour, zap.NewNop()).Stream)
+	r.POST("/transactions/:id/authorize", authorizations.Authorize)
+	r.POST("/transactions/:id/capture", authorizations.Capture)
+	r.POST("/transactions/:id/refunds", refunds.Create)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	stream := openStream(t, srv, feed, "?user_id=7")
+
+	steps := []struct {
+		path, body string
+		old, new   domain.TransactionStatus
+	}{
+		{"/transactions/1/authorize", "", domain.StatusPending, domain.StatusAuthorized},
+		{"/transactions/1/capture", "", domain.StatusAuthorized, domain.StatusCaptured},
+		{"/transactions/1/refunds", `{"amount":"10"}`, domain.StatusCaptured, domain.StatusPartiallyRefunded},
+	}
+
+	for _, step := range steps {
+		w := postRefund(r, step.path, step.body)
+		if w.Code >= http.StatusBadRequest {
+			t.Fatalf("%s: unexpected status %d: %s", step.path, w.Code, w.Body.String())
+		}
+
+		frame := readFrame(t, stream)
+		if !strings.Contains(frame, "event:updated") ||
+			!strings.Contains(frame, `"old_status":"`+string(step.old)+`"`) ||
+			!strings.Contains(frame, `"Status":"`+string(step.new)+`"`) {
+			t.Fatalf("%s: expected %s -> %s event, got %q", step.path, step.old, step.new, frame)
+		}
+	}
+}
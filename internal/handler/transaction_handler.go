@@ -1,219 +1,528 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"transaction-technical-test/internal/apierror"
 	"transaction-technical-test/internal/domain"
 	"transaction-technical-test/internal/service"
 )
 
 type TransactionHandler struct {
-	service *service.TransactionService
-	logger  *zap.Logger
+	service     *service.TransactionService
+	idempotency *service.IdempotencyService
+	// location dipakai untuk from/to berupa tanggal saja
+	location *time.Location
+	logger   *zap.Logger
 }
 
-func NewTransactionHandler(s *service.TransactionService, logger *zap.Logger) *TransactionHandler {
+func NewTransactionHandler(
+	s *service.TransactionService,
+	idempotency *service.IdempotencyService,
+	location *time.Location,
+	logger *zap.Logger,
+) *TransactionHandler {
 	return &TransactionHandler{
-		service: s,
-		logger:  logger,
+		service:     s,
+		idempotency: idempotency,
+		location:    location,
+		logger:      logger,
 	}
 }
 
+// CreateTransactionRequest menerima amount dalam satuan mayor (misal "1000.50"),
+// bukan minor unit. Currency kosong berarti domain.DefaultCurrency.
 type CreateTransactionRequest struct {
-	UserID uint    `json:"user_id" binding:"required"`
-	Amount float64 `json:"amount" binding:"required,gt=0"`
+	UserID   uint            `json:"user_id" binding:"required"`
+	Amount   json.Number     `json:"amount" binding:"required"`
+	Currency domain.Currency `json:"currency"`
 }
 
 type UpdateStatusRequest struct {
-	Status domain.TransactionStatus `json:"status" binding:"required"`
+	Status domain.TransactionStatus `json:"status" binding:"required,transaction_status"`
 }
 
 func (h *TransactionHandler) Create(c *gin.Context) {
 	var req CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("invalid create transaction request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		requestLogger(c, h.logger).Warn("invalid create transaction request", zap.Error(err))
+		bindingFailed(c, err)
 		return
 	}
 
-	tx, err := h.service.Create(req.UserID, req.Amount)
+	if req.Currency == "" {
+		req.Currency = domain.DefaultCurrency
+	}
+
+	// ParseMoney hanya membaca desimal ke minor unit (termasuk menolak mata
+	// uang tanpa exponent); aturan amount transaksi divalidasi service.Create
+	amount, err := domain.ParseMoney(req.Amount.String(), req.Currency)
 	if err != nil {
-		h.logger.Error("failed to create transaction",
-			zap.Uint("user_id", req.UserID),
-			zap.Float64("amount", req.Amount),
+		requestLogger(c, h.logger).Warn("invalid create transaction amount",
+			zap.String("amount", req.Amount.String()),
+			zap.String("currency", string(req.Currency)),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		_ = c.Error(err)
+		return
+	}
+
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key != "" && h.beginIdempotent(c, key, createFingerprint(req.UserID, amount)) {
 		return
 	}
 
-	h.logger.Info("transaction created",
+	tx, err := h.service.Create(c.Request.Context(), req.UserID, amount)
+	if err != nil {
+		h.releaseIdempotent(c, key)
+
+		fields := []zap.Field{
+			zap.Uint("user_id", req.UserID),
+			zap.String("amount", amount.String()),
+			zap.String("currency", string(amount.Currency)),
+			zap.Error(err),
+		}
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to create transaction", fields...)
+		} else {
+			requestLogger(c, h.logger).Warn("create transaction rejected", fields...)
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction created",
 		zap.Uint("transaction_id", tx.ID),
 		zap.Uint("user_id", tx.UserID),
-		zap.Float64("amount", tx.Amount),
+		zap.String("amount", tx.Amount.String()),
+		zap.String("currency", string(tx.Amount.Currency)),
 	)
 
-	c.JSON(http.StatusCreated, gin.H{
+	body, err := json.Marshal(gin.H{
 		"data": tx,
 	})
+	if err != nil {
+		h.releaseIdempotent(c, key)
+		requestLogger(c, h.logger).Error("failed to encode transaction", zap.Uint("transaction_id", tx.ID), zap.Error(err))
+		_ = c.Error(err)
+		return
+	}
+
+	h.completeIdempotent(c, key, http.StatusCreated, body)
+	c.Data(http.StatusCreated, jsonContentType, body)
 }
 
 func (h *TransactionHandler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		h.logger.Warn("invalid transaction id", zap.String("id", idStr))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": "invalid id",
-			},
-		})
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
 		return
 	}
 
-	tx, err := h.service.GetByID(uint(id))
+	tx, err := h.service.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		if err == domain.ErrTransactionNotFound {
-			h.logger.Info("transaction not found", zap.Uint("transaction_id", uint(id)))
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": gin.H{
-					"message": err.Error(),
-				},
-			})
+			requestLogger(c, h.logger).Info("transaction not found", zap.Uint("transaction_id", uint(id)))
+			_ = c.Error(err)
 			return
 		}
 
-		h.logger.Error("failed to get transaction",
+		requestLogger(c, h.logger).Error("failed to get transaction",
 			zap.Uint("transaction_id", uint(id)),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	h.logger.Info("transaction retrieved", zap.Uint("transaction_id", tx.ID))
+	requestLogger(c, h.logger).Info("transaction retrieved", zap.Uint("transaction_id", tx.ID))
 
+	c.Header(etagHeader, formatETag(tx.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": tx,
 	})
 }
 
+// filter membaca query filter list transaksi:
+//   - ?user_id= dan ?status= boleh diulang atau dipisah koma
+//   - ?from= dan ?to= berupa RFC3339 atau YYYY-MM-DD, keduanya inklusif (to
+//     tanggal saja mencakup seluruh hari itu)
+//   - ?min_amount= dan ?max_amount= dalam desimal, mata uang dari ?currency= (default IDR)
+//   - ?sort= seperti -amount,created_at (lihat domain.ParseSort)
+//   - ?include_deleted=true hanya untuk admin
+//
+// false berarti response error (400 atau 403) sudah ditulis.
+func (h *TransactionHandler) filter(c *gin.Context) (filter domain.TransactionFilter, ok bool) {
+	for _, userID := range queryValues(c, "user_id") {
+		id, err := strconv.ParseUint(userID, 10, 0)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid user_id query", zap.String("user_id", userID))
+			invalidParameter(c, "invalid user_id")
+			return filter, false
+		}
+		filter.UserIDs = append(filter.UserIDs, uint(id))
+	}
+
+	for _, value := range queryValues(c, "status") {
+		status, err := domain.ParseStatus(value)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid status query", zap.String("status", value))
+			validationFailed(c, statusFieldError("status"))
+			return filter, false
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if fromQuery := c.Query("from"); fromQuery != "" {
+		from, _, err := parseTimeQuery(fromQuery, h.location, false)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid from query", zap.String("from", fromQuery))
+			invalidParameter(c, "invalid from")
+			return filter, false
+		}
+		filter.From = &from
+	}
+
+	if toQuery := c.Query("to"); toQuery != "" {
+		to, dateOnly, err := parseTimeQuery(toQuery, h.location, true)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid to query", zap.String("to", toQuery))
+			invalidParameter(c, "invalid to")
+			return filter, false
+		}
+		filter.To, filter.ToExclusive = &to, dateOnly
+	}
+
+	currency := domain.Currency(c.DefaultQuery("currency", string(domain.DefaultCurrency)))
+	if filter.MinAmount, ok = h.amountQuery(c, "min_amount", currency); !ok {
+		return filter, false
+	}
+	if filter.MaxAmount, ok = h.amountQuery(c, "max_amount", currency); !ok {
+		return filter, false
+	}
+
+	if includeDeleted := c.Query("include_deleted"); includeDeleted != "" {
+		include, err := strconv.ParseBool(includeDeleted)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid include_deleted query", zap.String("include_deleted", includeDeleted))
+			invalidParameter(c, "invalid include_deleted")
+			return filter, false
+		}
+		if include && !requireAdmin(c) {
+			return filter, false
+		}
+		filter.IncludeDeleted = include
+	}
+
+	sort, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid sort query", zap.String("sort", c.Query("sort")))
+		_ = c.Error(err)
+		return filter, false
+	}
+	filter.Sort = sort
+
+	if err := filter.Validate(); err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction filter", zap.Error(err))
+		_ = c.Error(err)
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// amountQuery membaca amount desimal dari query key, nil jika kosong
+func (h *TransactionHandler) amountQuery(c *gin.Context, key string, currency domain.Currency) (*domain.Money, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, true
+	}
+
+	amount, err := domain.ParseMoney(value, currency)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid amount query", zap.String(key, value), zap.Error(err))
+		invalidParameter(c, "invalid "+key+": "+err.Error())
+		return nil, false
+	}
+	return &amount, true
+}
+
+// GetAll default memakai pagination OFFSET (?page=, default 1, meta page)
+// supaya client lama tetap jalan. Cursor pagination (meta next_cursor/
+// prev_cursor) dipakai jika ?cursor= diisi atau ?pagination=cursor untuk
+// halaman pertama. ?limit= maksimal domain.MaxPageSize di kedua mode. Meta
+// total dan total_pages dihitung dengan query COUNT kecuali ?count=false.
 func (h *TransactionHandler) GetAll(c *gin.Context) {
-	var filter domain.TransactionFilter
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
 
-	if userID := c.Query("user_id"); userID != "" {
-		id, err := strconv.Atoi(userID)
+	// ?count=false melewati query COUNT untuk tabel yang sangat besar,
+	// meta total dan total_pages tidak dikirim
+	withTotal := true
+	if countQuery := c.Query("count"); countQuery != "" {
+		b, err := strconv.ParseBool(countQuery)
 		if err != nil {
-			h.logger.Warn("invalid user_id query", zap.String("user_id", userID))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": gin.H{
-					"message": "invalid user_id",
-				},
-			})
+			requestLogger(c, h.logger).Warn("invalid count query", zap.String("count", countQuery))
+			invalidParameter(c, "invalid count")
 			return
 		}
-		uid := uint(id)
-		filter.UserID = &uid
+		withTotal = b
 	}
 
-	if status := c.Query("status"); status != "" {
-		s := domain.TransactionStatus(status)
-		filter.Status = &s
+	limit := domain.DefaultPageSize
+	if limitQuery := c.Query("limit"); limitQuery != "" {
+		n, err := strconv.Atoi(limitQuery)
+		if err != nil || n < 1 || n > domain.MaxPageSize {
+			requestLogger(c, h.logger).Warn("invalid limit query", zap.String("limit", limitQuery))
+			invalidParameter(c, "limit must be between 1 and "+strconv.Itoa(domain.MaxPageSize))
+			return
+		}
+		limit = n
 	}
+	filter.Limit = limit
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	cursorQuery := c.Query("cursor")
+	useCursor := cursorQuery != ""
+	switch pagination := c.Query("pagination"); pagination {
+	case "", paginationOffset:
+		if pagination == paginationOffset && useCursor {
+			invalidParameter(c, "cursor requires pagination=cursor")
+			return
+		}
+	case paginationCursor:
+		useCursor = true
+	default:
+		requestLogger(c, h.logger).Warn("invalid pagination query", zap.String("pagination", pagination))
+		invalidParameter(c, "pagination must be one of offset, cursor")
+		return
+	}
 
-	filter.Limit = limit
-	filter.Offset = (page - 1) * limit
+	if !useCursor {
+		page := 1
+		if pageQuery := c.Query("page"); pageQuery != "" {
+			n, err := strconv.Atoi(pageQuery)
+			if err != nil || n < 1 {
+				requestLogger(c, h.logger).Warn("invalid page query", zap.String("page", pageQuery))
+				invalidParameter(c, "invalid page")
+				return
+			}
+			page = n
+		}
+		filter.Offset = (page - 1) * limit
+
+		h.getPage(c, filter, page, withTotal)
+		return
+	}
+
+	if c.Query("page") != "" {
+		invalidParameter(c, "page cannot be combined with cursor pagination")
+		return
+	}
+
+	if cursorQuery != "" {
+		cursor, err := domain.DecodeCursor(cursorQuery)
+		if err != nil {
+			requestLogger(c, h.logger).Warn("invalid cursor query", zap.String("cursor", cursorQuery))
+			_ = c.Error(err)
+			return
+		}
+		if cursor.Sort != filter.Sort.String() {
+			requestLogger(c, h.logger).Warn("cursor sort mismatch",
+				zap.String("cursor_sort", cursor.Sort),
+				zap.String("sort", filter.Sort.String()),
+			)
+			_ = c.Error(domain.ErrCursorSortMismatch)
+			return
+		}
+		filter.Cursor = cursor
+	}
 
-	result, err := h.service.GetAll(filter)
+	result, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		h.logger.Error("failed to get transactions",
+		requestLogger(c, h.logger).Error("failed to get transactions",
 			zap.Any("filter", filter),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	h.logger.Info("transactions retrieved",
+	requestLogger(c, h.logger).Info("transactions retrieved",
+		zap.Int("count", len(result.Items)),
+		zap.Any("filter", filter),
+	)
+
+	meta := gin.H{
+		"count":       len(result.Items),
+		"limit":       limit,
+		"has_next":    result.NextCursor != nil,
+		"next_cursor": result.NextCursor,
+		"prev_cursor": result.PrevCursor,
+	}
+	if withTotal && !h.addTotal(c, filter, meta) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result.Items,
+		"meta": meta,
+	})
+}
+
+// Nilai ?pagination= untuk GetAll
+const (
+	paginationOffset = "offset"
+	paginationCursor = "cursor"
+)
+
+// getPage adalah mode pagination default dengan LIMIT/OFFSET
+func (h *TransactionHandler) getPage(c *gin.Context, filter domain.TransactionFilter, page int, withTotal bool) {
+	limit := filter.Limit
+	// satu baris ekstra untuk has_next tanpa bergantung pada COUNT
+	query := filter
+	query.Limit = limit + 1
+
+	result, err := h.service.GetAll(c.Request.Context(), query)
+	if err != nil {
+		requestLogger(c, h.logger).Error("failed to get transactions",
+			zap.Any("filter", filter),
+			zap.Error(err),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	hasNext := len(result) > limit
+	if hasNext {
+		result = result[:limit]
+	}
+
+	requestLogger(c, h.logger).Info("transactions retrieved",
 		zap.Int("count", len(result)),
 		zap.Any("filter", filter),
 	)
 
+	meta := gin.H{
+		"count":    len(result),
+		"page":     page,
+		"limit":    limit,
+		"has_next": hasNext,
+	}
+	if withTotal && !h.addTotal(c, filter, meta) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": result,
-		"meta": gin.H{
-			"count": len(result),
-			"page":  page,
-			"limit": limit,
-		},
+		"meta": meta,
 	})
 }
 
+// addTotal mengisi meta total dan total_pages. false berarti response
+// error sudah ditulis.
+func (h *TransactionHandler) addTotal(c *gin.Context, filter domain.TransactionFilter, meta gin.H) bool {
+	total, err := h.service.Count(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c, h.logger).Error("failed to count transactions",
+			zap.Any("filter", filter),
+			zap.Error(err),
+		)
+		_ = c.Error(err)
+		return false
+	}
+
+	limit := int64(filter.Limit)
+	meta["total"] = total
+	meta["total_pages"] = (total + limit - 1) / limit
+	return true
+}
+
 func (h *TransactionHandler) UpdateStatus(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		h.logger.Warn("invalid transaction id", zap.String("id", idStr))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": "invalid id",
-			},
-		})
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
 		return
 	}
 
-	var req UpdateStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("invalid update status request",
+	// If-Match wajib supaya update tidak menimpa perubahan request lain
+	ifMatch := c.GetHeader(ifMatchHeader)
+	if ifMatch == "" {
+		requestLogger(c, h.logger).Warn("missing If-Match header", zap.Uint("transaction_id", uint(id)))
+		_ = c.Error(apierror.New(http.StatusPreconditionRequired, apierror.CodePreconditionRequired, "If-Match header is required"))
+		return
+	}
+
+	versions, anyVersion, err := parseIfMatch(ifMatch)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid If-Match header",
 			zap.Uint("transaction_id", uint(id)),
-			zap.Error(err),
+			zap.String("if_match", ifMatch),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		_ = c.Error(apierror.BadRequest(apierror.CodeInvalidHeader, err.Error()))
 		return
 	}
 
-	if err := h.service.UpdateStatus(uint(id), req.Status); err != nil {
-		h.logger.Error("failed to update transaction status",
+	// hanya berisi weak ETag, tidak ada yang bisa cocok
+	if !anyVersion && len(versions) == 0 {
+		requestLogger(c, h.logger).Warn("weak If-Match never matches",
+			zap.Uint("transaction_id", uint(id)),
+			zap.String("if_match", ifMatch),
+		)
+		_ = c.Error(apierror.WithStatus(http.StatusPreconditionFailed, domain.ErrConcurrentModification))
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c, h.logger).Warn("invalid update status request",
 			zap.Uint("transaction_id", uint(id)),
-			zap.String("status", string(req.Status)),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		bindingFailed(c, err)
 		return
 	}
 
-	h.logger.Info("transaction status updated",
+	if err := h.service.UpdateStatus(c.Request.Context(), uint(id), req.Status, versions); err != nil {
+		if errors.Is(err, domain.ErrConcurrentModification) {
+			requestLogger(c, h.logger).Warn("transaction version mismatch",
+				zap.Uint("transaction_id", uint(id)),
+				zap.String("if_match", ifMatch),
+			)
+			_ = c.Error(apierror.WithStatus(http.StatusPreconditionFailed, err))
+			return
+		}
+
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to update transaction status",
+				zap.Uint("transaction_id", uint(id)),
+				zap.String("status", string(req.Status)),
+				zap.Error(err),
+			)
+		} else {
+			requestLogger(c, h.logger).Warn("update transaction status rejected",
+				zap.Uint("transaction_id", uint(id)),
+				zap.String("status", string(req.Status)),
+				zap.Error(err),
+			)
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction status updated",
 		zap.Uint("transaction_id", uint(id)),
 		zap.String("status", string(req.Status)),
 	)
@@ -221,43 +530,116 @@ func (h *TransactionHandler) UpdateStatus(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func (h *TransactionHandler) Delete(c *gin.Context) {
+// History mengembalikan audit trail transaksi urut dari yang paling lama
+func (h *TransactionHandler) History(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		h.logger.Warn("invalid transaction id", zap.String("id", idStr))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"message": "invalid id",
-			},
-		})
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
-		if err == domain.ErrTransactionNotFound {
-			h.logger.Warn("transaction not found", zap.Uint("transaction_id", uint(id)))
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": gin.H{
-					"message": err.Error(),
-				},
-			})
+	events, err := h.service.History(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionNotFound) {
+			requestLogger(c, h.logger).Info("transaction history not found", zap.Uint("transaction_id", uint(id)))
+			_ = c.Error(err)
 			return
 		}
 
-		h.logger.Error("failed to delete transaction",
+		requestLogger(c, h.logger).Error("failed to get transaction history",
 			zap.Uint("transaction_id", uint(id)),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"message": err.Error(),
-			},
-		})
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction history retrieved",
+		zap.Uint("transaction_id", uint(id)),
+		zap.Int("count", len(events)),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+	})
+}
+
+func (h *TransactionHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		if err == domain.ErrTransactionNotFound {
+			requestLogger(c, h.logger).Warn("transaction not found", zap.Uint("transaction_id", uint(id)))
+			_ = c.Error(err)
+			return
+		}
+
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to delete transaction",
+				zap.Uint("transaction_id", uint(id)),
+				zap.Error(err),
+			)
+		} else {
+			requestLogger(c, h.logger).Warn("delete transaction rejected",
+				zap.Uint("transaction_id", uint(id)),
+				zap.Error(err),
+			)
+		}
+		_ = c.Error(err)
 		return
 	}
 
-	h.logger.Info("transaction deleted", zap.Uint("transaction_id", uint(id)))
+	requestLogger(c, h.logger).Info("transaction deleted", zap.Uint("transaction_id", uint(id)))
 
 	c.Status(http.StatusNoContent)
 }
+
+// Restore membatalkan soft delete transaksi, hanya untuk admin
+func (h *TransactionHandler) Restore(c *gin.Context) {
+	if !requireAdmin(c) {
+		requestLogger(c, h.logger).Warn("restore rejected for non-admin",
+			zap.String("actor", domain.AuditInfoFromContext(c.Request.Context()).Actor),
+		)
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid transaction id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	tx, err := h.service.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		if isServerError(err) {
+			requestLogger(c, h.logger).Error("failed to restore transaction",
+				zap.Uint("transaction_id", uint(id)),
+				zap.Error(err),
+			)
+		} else {
+			requestLogger(c, h.logger).Warn("restore rejected",
+				zap.Uint("transaction_id", uint(id)),
+				zap.Error(err),
+			)
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("transaction restored", zap.Uint("transaction_id", tx.ID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tx,
+	})
+}
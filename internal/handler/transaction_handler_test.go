@@ -2,19 +2,24 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
+	"transaction-technical-test/internal/apierror"
 	"transaction-technical-test/internal/domain"
 	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/middleware"
 	"transaction-technical-test/internal/service"
 )
 
@@ -22,47 +27,161 @@ type mockTransactionRepo struct {
 	createFn   func(tx *domain.Transaction) error
 	findByIDFn func(id uint) (*domain.Transaction, error)
 	findAllFn  func(filter domain.TransactionFilter) ([]domain.Transaction, error)
+	countFn    func(filter domain.TransactionFilter) (int64, error)
 	updateFn   func(tx *domain.Transaction) error
 	deleteFn   func(id uint) error
+	historyFn  func(id uint) ([]domain.TransactionEvent, error)
+	restoreFn  func(id uint) error
 }
 
-func (m *mockTransactionRepo) Create(tx *domain.Transaction) error {
+// Seperti GORM dengan WithContext, mock gagal jika context sudah batal
+func (m *mockTransactionRepo) Create(ctx context.Context, tx *domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return m.createFn(tx)
 }
-func (m *mockTransactionRepo) FindByID(id uint) (*domain.Transaction, error) {
+func (m *mockTransactionRepo) FindByID(ctx context.Context, id uint) (*domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return m.findByIDFn(id)
 }
-func (m *mockTransactionRepo) FindAll(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+func (m *mockTransactionRepo) FindByIDForUpdate(ctx context.Context, id uint) (*domain.Transaction, error) {
+	return m.FindByID(ctx, id)
+}
+func (m *mockTransactionRepo) FindAll(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return m.findAllFn(filter)
 }
-func (m *mockTransactionRepo) Update(tx *domain.Transaction) error {
+func (m *mockTransactionRepo) Count(ctx context.Context, filter domain.TransactionFilter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if m.countFn == nil {
+		return 0, nil
+	}
+	return m.countFn(filter)
+}
+func (m *mockTransactionRepo) Update(ctx context.Context, tx *domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return m.updateFn(tx)
 }
-func (m *mockTransactionRepo) Delete(id uint) error {
+func (m *mockTransactionRepo) Delete(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return m.deleteFn(id)
 }
 
-func (m *mockTransactionRepo) TotalSuccessToday() (float64, error) { return 0, nil }
-func (m *mockTransactionRepo) AverageAmountPerUser() (float64, error) {
+func (m *mockTransactionRepo) FindExpiredAuthorizations(context.Context, time.Time, int) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) Restore(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.restoreFn(id)
+}
+func (m *mockTransactionRepo) PurgeDeleted(context.Context, time.Time) (int64, error) {
 	return 0, nil
 }
-func (m *mockTransactionRepo) Latest(limit int) ([]domain.Transaction, error) {
+func (m *mockTransactionRepo) History(ctx context.Context, id uint) ([]domain.TransactionEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.historyFn(id)
+}
+
+func (m *mockTransactionRepo) TotalSuccessToday(context.Context, *time.Location) ([]domain.Money, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) AverageTransactionAmount(context.Context) ([]domain.Money, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) Latest(ctx context.Context, limit int) ([]domain.Transaction, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) VolumeByBucket(context.Context, []time.Time, *domain.TransactionStatus) ([]domain.BucketVolume, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) StatusVolumeByBucket(context.Context, []time.Time) ([]domain.BucketStatusVolume, error) {
 	return nil, nil
 }
+func (m *mockTransactionRepo) AmountSummary(context.Context, domain.AmountFilter) (domain.AmountSummary, error) {
+	return domain.AmountSummary{}, nil
+}
+func (m *mockTransactionRepo) AmountsAtRanks(context.Context, domain.AmountFilter, []int64) ([]domain.Money, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) AmountHistogram(context.Context, domain.AmountFilter, []int64) ([]domain.HistogramCount, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) TopUsers(context.Context, domain.TopUsersFilter) ([]domain.UserVolume, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) AverageTotalPerUser(context.Context) ([]domain.Money, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) UserVolumeByStatus(context.Context, uint) ([]domain.StatusVolume, error) {
+	return nil, nil
+}
+func (m *mockTransactionRepo) UserActivity(context.Context, uint) (*time.Time, *time.Time, error) {
+	return nil, nil, nil
+}
+
+// newTestRouter memasang middleware.Errors seperti di main, karena handler
+// hanya mencatat error dan tidak menulis response error sendiri
+// moneyBody adalah bentuk JSON domain.Money di response
+type moneyBody struct {
+	Amount   string          `json:"amount"`
+	Currency domain.Currency `json:"currency"`
+}
+
+// testAdminToken adalah token yang dianggap admin oleh newTestRouter
+const testAdminToken = "admin-secret"
+
+func newTestRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()), middleware.Audit([]string{testAdminToken}))
+	return r
+}
+
+func asAdmin(req *http.Request) {
+	req.Header.Set(middleware.AuthorizationHeader, "Bearer "+testAdminToken)
+}
+
 func setupTransactionRouter(repo *mockTransactionRepo) *gin.Engine {
+	return setupTransactionRouterWithWallets(repo, &mockWalletRepo{})
+}
+
+func setupTransactionRouterWithWallets(repo *mockTransactionRepo, wallets *mockWalletRepo) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
-	svc := service.NewTransactionService(repo)
+	svc := service.NewTransactionService(
+		mockTransactor{},
+		repo,
+		service.NewLedgerService(&mockLedgerRepo{}),
+		service.NewWalletService(wallets),
+		service.NewBroadcaster(1, zap.NewNop()),
+	)
+	idempotency := service.NewIdempotencyService(newMockIdempotencyRepo(), time.Hour)
 
 	logger := zap.NewNop()
-	h := handler.NewTransactionHandler(svc, logger)
+	h := handler.NewTransactionHandler(svc, idempotency, time.UTC, logger)
 
-	r := gin.New()
+	r := newTestRouter()
 	r.POST("/transactions", h.Create)
 	r.GET("/transactions/:id", h.GetByID)
 	r.GET("/transactions", h.GetAll)
 	r.PUT("/transactions/:id", h.UpdateStatus)
 	r.DELETE("/transactions/:id", h.Delete)
+	r.GET("/transactions/:id/history", h.History)
+	r.POST("/transactions/:id/restore", h.Restore)
 
 	return r
 }
@@ -101,6 +220,27 @@ func TestTransactionHandler_Create_InvalidBody(t *testing.T) {
 		t.Fatalf("expected 400")
 	}
 }
+func TestTransactionHandler_Create_InvalidAmount(t *testing.T) {
+	repo := &mockTransactionRepo{}
+	r := setupTransactionRouter(repo)
+
+	bodies := []string{
+		`{"user_id":1,"amount":10.001}`,
+		`{"user_id":1,"amount":-5}`,
+		`{"user_id":1,"amount":0}`,
+		`{"user_id":1,"amount":1000,"currency":"XXX"}`,
+	}
+
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
 func TestTransactionHandler_GetByID_Success(t *testing.T) {
 	repo := &mockTransactionRepo{
 		findByIDFn: func(id uint) (*domain.Transaction, error) {
@@ -153,7 +293,7 @@ func TestTransactionHandler_GetAll_Success(t *testing.T) {
 	}
 }
 func TestTransactionHandler_UpdateStatus_Success(t *testing.T) {
-	tx := &domain.Transaction{ID: 1}
+	tx := &domain.Transaction{ID: 1, UserID: 1, Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}, Status: domain.StatusPending, Version: 1}
 
 	repo := &mockTransactionRepo{
 		findByIDFn: func(id uint) (*domain.Transaction, error) {
@@ -164,13 +304,17 @@ func TestTransactionHandler_UpdateStatus_Success(t *testing.T) {
 		},
 	}
 
-	r := setupTransactionRouter(repo)
+	wallets := &mockWalletRepo{
+		wallets: []domain.Wallet{{ID: 1, UserID: 1, Balance: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}}},
+	}
+	r := setupTransactionRouterWithWallets(repo, wallets)
 
 	body := map[string]string{"status": string(domain.StatusSuccess)}
 	b, _ := json.Marshal(body)
 
 	req := httptest.NewRequest(http.MethodPut, "/transactions/1", bytes.NewBuffer(b))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
@@ -179,6 +323,34 @@ func TestTransactionHandler_UpdateStatus_Success(t *testing.T) {
 		t.Fatalf("expected 204")
 	}
 }
+func TestTransactionHandler_UpdateStatus_InsufficientFunds(t *testing.T) {
+	tx := &domain.Transaction{ID: 1, UserID: 1, Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}, Status: domain.StatusPending}
+
+	repo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			return tx, nil
+		},
+		updateFn: func(tx *domain.Transaction) error {
+			return nil
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	body := map[string]string{"status": string(domain.StatusSuccess)}
+	b, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPut, "/transactions/1", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+}
 func TestTransactionHandler_UpdateStatus_Invalid(t *testing.T) {
 	repo := &mockTransactionRepo{
 		findByIDFn: func(id uint) (*domain.Transaction, error) {
@@ -194,6 +366,7 @@ func TestTransactionHandler_UpdateStatus_Invalid(t *testing.T) {
 		bytes.NewBufferString(`{"status":"invalid"}`),
 	)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
@@ -201,6 +374,70 @@ func TestTransactionHandler_UpdateStatus_Invalid(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400")
 	}
+
+	var body struct {
+		Code   string                `json:"code"`
+		Errors []apierror.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(body.Errors) != 1 {
+		t.Fatalf("expected 1 field error, got %+v", body.Errors)
+	}
+	assert.Equal(t, apierror.CodeValidationFailed, body.Code)
+	assert.Equal(t, "status", body.Errors[0].Field)
+	assert.Len(t, body.Errors[0].Allowed, len(domain.Statuses))
+	assert.Contains(t, body.Errors[0].Allowed, "success")
+}
+
+func TestTransactionHandler_UpdateStatus_MissingStatus(t *testing.T) {
+	r := setupTransactionRouter(&mockTransactionRepo{})
+
+	req := httptest.NewRequest(http.MethodPut, "/transactions/1", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "request validation failed",
+		"instance": "/transactions/1",
+		"code": "validation_failed",
+		"errors": [{"field": "status", "message": "is required"}]
+	}`, w.Body.String())
+}
+func TestTransactionHandler_UpdateStatus_InvalidTransition(t *testing.T) {
+	repo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, Status: domain.StatusSuccess}, nil
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/transactions/1",
+		bytes.NewBufferString(`{"status":"pending"}`),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
 }
 func TestTransactionHandler_Delete_Success(t *testing.T) {
 	repo := &mockTransactionRepo{
@@ -276,7 +513,7 @@ func TestTransactionHandler_Create_ServiceError(t *testing.T) {
 func TestTransactionHandler_GetAll_Detailed(t *testing.T) {
 	repo := &mockTransactionRepo{
 		findAllFn: func(filter domain.TransactionFilter) ([]domain.Transaction, error) {
-			if filter.UserID != nil && *filter.UserID == 1 {
+			if len(filter.UserIDs) == 1 && filter.UserIDs[0] == 1 {
 				return []domain.Transaction{{ID: 1}}, nil
 			}
 			return []domain.Transaction{}, nil
@@ -311,9 +548,10 @@ func TestTransactionHandler_EdgeCases(t *testing.T) {
 		}
 		body := `{"status":"success"}`
 		req := httptest.NewRequest(http.MethodPut, "/transactions/99", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", "*")
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 
 	t.Run("Delete - Internal Error", func(t *testing.T) {
@@ -324,6 +562,9 @@ func TestTransactionHandler_EdgeCases(t *testing.T) {
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		// pesan error repository tidak boleh sampai ke client
+		assert.NotContains(t, w.Body.String(), "disk failure")
+		assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
 	})
 }
 func TestTransactionHandler_Delete_InvalidID(t *testing.T) {
@@ -355,6 +596,27 @@ func TestTransactionHandler_Delete_NotFound(t *testing.T) {
 		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
+func TestTransactionHandler_Delete_ActiveAuthorization(t *testing.T) {
+	repo := &mockTransactionRepo{
+		deleteFn: func(id uint) error {
+			return domain.ErrAuthorizationActive
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/transactions/1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"code":"authorization_active"`) {
+		t.Fatalf("expected authorization_active code, got %s", w.Body.String())
+	}
+}
+
 func TestTransactionHandler_UpdateStatus_InvalidID(t *testing.T) {
 	repo := &mockTransactionRepo{}
 	r := setupTransactionRouter(repo)
@@ -365,6 +627,7 @@ func TestTransactionHandler_UpdateStatus_InvalidID(t *testing.T) {
 		bytes.NewBufferString(`{"status":"success"}`),
 	)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
 
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
@@ -383,11 +646,559 @@ func TestTransactionHandler_UpdateStatus_InvalidJSON(t *testing.T) {
 		bytes.NewBufferString(`{"status":123}`), // wrong type
 	)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+func TestTransactionHandler_ContextErrors(t *testing.T) {
+	repo := &mockTransactionRepo{
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id}, nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	t.Run("Client Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest(http.MethodGet, "/transactions/1", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, 499, w.Code)
+	})
+
+	t.Run("Deadline Exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+
+		req := httptest.NewRequest(
+			http.MethodPut,
+			"/transactions/1",
+			bytes.NewBufferString(`{"status":"success"}`),
+		).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	})
+}
+
+func TestTransactionHandler_History(t *testing.T) {
+	repo := &mockTransactionRepo{
+		historyFn: func(id uint) ([]domain.TransactionEvent, error) {
+			if id == 3 {
+				return []domain.TransactionEvent{}, nil
+			}
+			if id != 1 {
+				return nil, domain.ErrTransactionNotFound
+			}
+			amount := domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}
+			return []domain.TransactionEvent{
+				{ID: 1, TransactionID: 1, Type: domain.EventTransactionCreated,
+					OldAmount: domain.Money{Currency: domain.CurrencyIDR}, NewAmount: amount, NewStatus: domain.StatusPending},
+				{ID: 2, TransactionID: 1, Type: domain.EventTransactionUpdated,
+					OldAmount: amount, NewAmount: amount, OldStatus: domain.StatusPending, NewStatus: domain.StatusSuccess},
+			}, nil
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	t.Run("Success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/1/history", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+
+		var body struct {
+			Data []domain.TransactionEvent `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if len(body.Data) != 2 || body.Data[1].NewStatus != domain.StatusSuccess {
+			t.Fatalf("unexpected history: %+v", body.Data)
+		}
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/2/history", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/3/history", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
 
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"data":[]`) {
+			t.Fatalf("expected empty data list, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestTransactionHandler_Restore(t *testing.T) {
+	deleted := map[uint]bool{1: true}
+	repo := &mockTransactionRepo{
+		restoreFn: func(id uint) error {
+			if id == 3 {
+				return domain.ErrTransactionNotFound
+			}
+			if !deleted[id] {
+				return domain.ErrNotDeleted
+			}
+			deleted[id] = false
+			return nil
+		},
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			return &domain.Transaction{ID: id, Amount: domain.Money{Minor: 100, Currency: domain.CurrencyIDR}}, nil
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	tests := []struct {
+		name string
+		id   string
+		want int
+	}{
+		{"Success", "1", http.StatusOK},
+		{"Not Deleted", "2", http.StatusConflict},
+		{"Not Found", "3", http.StatusNotFound},
+		{"Invalid ID", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/transactions/"+tt.id+"/restore", nil)
+			asAdmin(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+
+	t.Run("Non Admin", func(t *testing.T) {
+		deleted[1] = true
+
+		// X-Actor bisa diisi siapa saja, tanpa token tetap bukan admin
+		for _, auth := range []string{"", "Bearer wrong-secret"} {
+			req := httptest.NewRequest(http.MethodPost, "/transactions/1/restore", nil)
+			req.Header.Set(middleware.ActorHeader, "admin@example.com")
+			if auth != "" {
+				req.Header.Set(middleware.AuthorizationHeader, auth)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"code":"admin_required"`) {
+				t.Fatalf("authorization %q: expected 403 admin_required, got %d %s", auth, w.Code, w.Body.String())
+			}
+		}
+		if !deleted[1] {
+			t.Fatalf("non-admin must not restore the transaction")
+		}
+	})
+}
+
+func TestTransactionHandler_GetAll_IncludeDeleted(t *testing.T) {
+	var got domain.TransactionFilter
+	repo := &mockTransactionRepo{
+		findAllFn: func(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+			got = filter
+			return nil, nil
+		},
+	}
+
+	r := setupTransactionRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions?include_deleted=true", nil)
+	asAdmin(req)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
+	if w.Code != http.StatusOK || !got.IncludeDeleted {
+		t.Fatalf("expected include_deleted filter, got %d %+v", w.Code, got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/transactions?include_deleted=maybe", nil)
+	asAdmin(req)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
+
+	t.Run("Non Admin", func(t *testing.T) {
+		got = domain.TransactionFilter{}
+
+		req := httptest.NewRequest(http.MethodGet, "/transactions?include_deleted=true", nil)
+		req.Header.Set(middleware.ActorHeader, "admin@example.com")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"code":"admin_required"`) {
+			t.Fatalf("expected 403 admin_required, got %d %s", w.Code, w.Body.String())
+		}
+		if got.IncludeDeleted {
+			t.Fatalf("non-admin must not list deleted transactions")
+		}
+
+		// include_deleted=false tidak butuh admin
+		req = httptest.NewRequest(http.MethodGet, "/transactions?include_deleted=false", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	})
+}
+
+func TestTransactionHandler_GetAll_Cursor(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var got domain.TransactionFilter
+	repo := &mockTransactionRepo{
+		findAllFn: func(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+			got = filter
+			return []domain.Transaction{{ID: 3, CreatedAt: created}, {ID: 2, CreatedAt: created}, {ID: 1, CreatedAt: created}}, nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions?pagination=cursor&limit=2", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var body struct {
+		Meta struct {
+			Count      int     `json:"count"`
+			NextCursor *string `json:"next_cursor"`
+			PrevCursor *string `json:"prev_cursor"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if body.Meta.Count != 2 || body.Meta.NextCursor == nil || body.Meta.PrevCursor != nil {
+		t.Fatalf("unexpected meta: %+v", body.Meta)
+	}
+
+	// next_cursor dari response dipakai apa adanya di request berikutnya
+	req = httptest.NewRequest(http.MethodGet, "/transactions?limit=2&cursor="+*body.Meta.NextCursor, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got.Cursor == nil || got.Cursor.ID != 2 || got.Cursor.Backward {
+		t.Fatalf("expected cursor after id 2, got %+v", got.Cursor)
+	}
+
+	// tanpa cursor dan pagination tetap mode OFFSET untuk client lama
+	req = httptest.NewRequest(http.MethodGet, "/transactions?limit=2", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || got.Cursor != nil || got.Offset != 0 {
+		t.Fatalf("expected offset mode by default, got %d %+v", w.Code, got)
+	}
+	if !strings.Contains(w.Body.String(), `"page":1`) || strings.Contains(w.Body.String(), "next_cursor") {
+		t.Fatalf("expected legacy page meta, got %s", w.Body.String())
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Invalid Cursor", query: "cursor=garbage"},
+		{name: "Limit Too Large", query: "limit=101"},
+		{name: "Zero Limit", query: "limit=0"},
+		{name: "Invalid Page", query: "page=0"},
+		{name: "Unknown Pagination", query: "pagination=keyset"},
+		{name: "Page With Cursor Pagination", query: "pagination=cursor&page=2"},
+		{name: "Page With Cursor", query: "page=2&cursor=" + *body.Meta.NextCursor},
+		{name: "Cursor With Offset Pagination", query: "pagination=offset&cursor=" + *body.Meta.NextCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/transactions?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_GetAll_Total(t *testing.T) {
+	counted := false
+	repo := &mockTransactionRepo{
+		findAllFn: func(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+			n := filter.Limit
+			if filter.Offset >= 20 {
+				n = 3
+			}
+			return make([]domain.Transaction, n), nil
+		},
+		countFn: func(filter domain.TransactionFilter) (int64, error) {
+			counted = true
+			return 23, nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	type meta struct {
+		Count      int    `json:"count"`
+		HasNext    bool   `json:"has_next"`
+		Total      *int64 `json:"total"`
+		TotalPages *int64 `json:"total_pages"`
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		counted bool
+		want    meta
+	}{
+		{name: "Default", query: "limit=10", counted: true, want: meta{Count: 10, HasNext: true}},
+		{name: "Legacy Page", query: "page=1&limit=10", counted: true, want: meta{Count: 10, HasNext: true}},
+		{name: "Legacy Last Page", query: "page=3&limit=10", counted: true, want: meta{Count: 3, HasNext: false}},
+		{name: "Cursor", query: "pagination=cursor&limit=10", counted: true, want: meta{Count: 10, HasNext: true}},
+		{name: "Skip Count", query: "page=1&limit=10&count=false", counted: false, want: meta{Count: 10, HasNext: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counted = false
+			req := httptest.NewRequest(http.MethodGet, "/transactions?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", w.Code)
+			}
+
+			var body struct {
+				Meta meta `json:"meta"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid json: %v", err)
+			}
+
+			assert.Equal(t, tt.counted, counted)
+			assert.Equal(t, tt.want.Count, body.Meta.Count)
+			assert.Equal(t, tt.want.HasNext, body.Meta.HasNext)
+			if tt.counted {
+				assert.Equal(t, int64(23), *body.Meta.Total)
+				assert.Equal(t, int64(3), *body.Meta.TotalPages)
+			} else {
+				assert.Nil(t, body.Meta.Total)
+				assert.Nil(t, body.Meta.TotalPages)
+			}
+		})
+	}
+
+	t.Run("No Query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var body struct {
+			Meta struct {
+				meta
+				Page  int `json:"page"`
+				Limit int `json:"limit"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, body.Meta.Page)
+		assert.Equal(t, domain.DefaultPageSize, body.Meta.Limit)
+		assert.True(t, body.Meta.HasNext)
+		if assert.NotNil(t, body.Meta.Total) && assert.NotNil(t, body.Meta.TotalPages) {
+			assert.Equal(t, int64(23), *body.Meta.Total)
+			assert.Equal(t, (int64(23)+int64(domain.DefaultPageSize)-1)/int64(domain.DefaultPageSize), *body.Meta.TotalPages)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions?count=maybe", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid count, got %d", w.Code)
+	}
+}
+
+func TestTransactionHandler_GetAll_Filters(t *testing.T) {
+	var got domain.TransactionFilter
+	repo := &mockTransactionRepo{
+		findAllFn: func(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+			got = filter
+			return []domain.Transaction{}, nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/transactions?user_id=1,2&user_id=3&status=success&status=failed"+
+			"&from=2024-01-01&to=2024-01-31&min_amount=10.50&max_amount=100&sort=-amount", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	assert.Equal(t, []uint{1, 2, 3}, got.UserIDs)
+	assert.Equal(t, []domain.TransactionStatus{domain.StatusSuccess, domain.StatusFailed}, got.Statuses)
+	assert.True(t, got.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	// to berupa tanggal saja inklusif, batas eksklusifnya awal hari berikutnya
+	assert.True(t, got.To.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, got.ToExclusive)
+	assert.Equal(t, &domain.Money{Minor: 1050, Currency: domain.CurrencyIDR}, got.MinAmount)
+	assert.Equal(t, &domain.Money{Minor: 10000, Currency: domain.CurrencyIDR}, got.MaxAmount)
+	assert.Equal(t, "currency,-amount,-id", got.Sort.String())
+
+	t.Run("RFC3339 To Is Inclusive", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?to=2024-01-31T10:00:00Z", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		assert.True(t, got.To.Equal(time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)))
+		assert.False(t, got.ToExclusive)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Invalid User ID", query: "user_id=1,abc"},
+		{name: "Invalid From", query: "from=yesterday"},
+		{name: "Invalid To", query: "to=2024-13-01"},
+		{name: "Inverted Time Range", query: "from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{name: "Invalid Amount", query: "min_amount=-5"},
+		{name: "Too Many Decimals", query: "max_amount=1.001"},
+		{name: "Inverted Amount Range", query: "min_amount=100&max_amount=10"},
+		{name: "Invalid Currency", query: "currency=XXX&min_amount=10"},
+		{name: "Unknown Status", query: "status=success,sucess"},
+		{name: "Invalid Sort", query: "sort=amount%20desc%2C%28select%201%29"},
+		{name: "Cursor From Other Sort", query: "sort=-amount&cursor=" + domain.NewCursor(domain.Transaction{ID: 1, CreatedAt: time.Now()}, domain.DefaultSort, false).Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/transactions?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_ETag(t *testing.T) {
+	tx := domain.Transaction{ID: 1, UserID: 1, Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}, Status: domain.StatusPending, Version: 4}
+	repo := &mockTransactionRepo{
+		// Salinan baru setiap kali, seperti baris yang dibaca ulang dari database
+		findByIDFn: func(id uint) (*domain.Transaction, error) {
+			copied := tx
+			return &copied, nil
+		},
+		updateFn: func(*domain.Transaction) error {
+			return nil
+		},
+	}
+	r := setupTransactionRouter(repo)
+
+	t.Run("GET Exposes Version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	})
+
+	put := func(ifMatch string) int {
+		req := httptest.NewRequest(http.MethodPut, "/transactions/1", bytes.NewBufferString(`{"status":"failed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Missing If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionRequired, put(""))
+	})
+
+	t.Run("Malformed If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, put("4"))
+	})
+
+	t.Run("Stale If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionFailed, put(`"3"`))
+	})
+
+	t.Run("Concurrent Update Lost Race", func(t *testing.T) {
+		repo.updateFn = func(*domain.Transaction) error {
+			return domain.ErrConcurrentModification
+		}
+		defer func() { repo.updateFn = func(*domain.Transaction) error { return nil } }()
+
+		assert.Equal(t, http.StatusPreconditionFailed, put(`"4"`))
+	})
+
+	// If-Match memakai strong comparison, weak ETag tidak pernah cocok
+	t.Run("Weak If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionFailed, put(`W/"4"`))
+	})
+
+	t.Run("If-Match List", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, put(`"3", "4"`))
+		assert.Equal(t, http.StatusPreconditionFailed, put(`"3", W/"4"`))
+		assert.Equal(t, http.StatusPreconditionFailed, put(`"2", "3"`))
+		assert.Equal(t, http.StatusBadRequest, put(`"3", 4`))
+		assert.Equal(t, http.StatusBadRequest, put(`"4", *`))
+	})
 }
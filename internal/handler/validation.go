@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"transaction-technical-test/internal/apierror"
+	"transaction-technical-test/internal/domain"
+)
+
+// tagTransactionStatus adalah tag binding untuk field domain.TransactionStatus
+const tagTransactionStatus = "transaction_status"
+
+// Validator gin didaftarkan saat package di-load supaya tag custom sudah
+// tersedia sebelum request pertama, termasuk di test
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// nama field di error mengikuti json tag, bukan nama field Go
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation(tagTransactionStatus, func(fl validator.FieldLevel) bool {
+		return domain.TransactionStatus(fl.Field().String()).IsValid()
+	})
+}
+
+// statusFieldError adalah FieldError untuk status transaksi yang tidak dikenal
+func statusFieldError(field string) apierror.FieldError {
+	allowed := make([]string, len(domain.Statuses))
+	for i, s := range domain.Statuses {
+		allowed[i] = string(s)
+	}
+	return apierror.FieldError{
+		Field:   field,
+		Message: "must be one of " + strings.Join(allowed, ", "),
+		Allowed: allowed,
+	}
+}
+
+// fieldErrors mengubah error validator menjadi FieldError, nil jika err
+// bukan error validasi (misal JSON rusak)
+func fieldErrors(err error) []apierror.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	result := make([]apierror.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		switch fe.Tag() {
+		case tagTransactionStatus:
+			result = append(result, statusFieldError(fe.Field()))
+		case "required":
+			result = append(result, apierror.FieldError{Field: fe.Field(), Message: "is required"})
+		case "max":
+			result = append(result, apierror.FieldError{Field: fe.Field(), Message: "must be at most " + fe.Param() + " characters"})
+		default:
+			result = append(result, apierror.FieldError{Field: fe.Field(), Message: "failed on " + fe.Tag() + " validation"})
+		}
+	}
+	return result
+}
+
+// validationFailed mencatat 400 dengan daftar FieldError
+func validationFailed(c *gin.Context, fields ...apierror.FieldError) {
+	_ = c.Error(apierror.Validation(fields...))
+}
+
+// bindingFailed mencatat 400 untuk error ShouldBindJSON: FieldError jika
+// error validasi, CodeInvalidBody jika body tidak bisa di-decode
+func bindingFailed(c *gin.Context, err error) {
+	if fields := fieldErrors(err); fields != nil {
+		validationFailed(c, fields...)
+		return
+	}
+	_ = c.Error(apierror.BadRequest(apierror.CodeInvalidBody, err.Error()))
+}
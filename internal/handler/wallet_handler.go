@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/service"
+)
+
+type WalletHandler struct {
+	service *service.WalletService
+	logger  *zap.Logger
+}
+
+func NewWalletHandler(s *service.WalletService, logger *zap.Logger) *WalletHandler {
+	return &WalletHandler{
+		service: s,
+		logger:  logger,
+	}
+}
+
+func (h *WalletHandler) GetByUserID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		requestLogger(c, h.logger).Warn("invalid user id", zap.String("id", idStr))
+		invalidParameter(c, "invalid id")
+		return
+	}
+
+	wallets, err := h.service.GetByUserID(c.Request.Context(), uint(id))
+	if err != nil {
+		requestLogger(c, h.logger).Error("failed to get wallets",
+			zap.Uint("user_id", uint(id)),
+			zap.Error(err),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	requestLogger(c, h.logger).Info("wallets retrieved",
+		zap.Uint("user_id", uint(id)),
+		zap.Int("count", len(wallets)),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": wallets,
+	})
+}
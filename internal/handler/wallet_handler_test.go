@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/handler"
+	"transaction-technical-test/internal/service"
+)
+
+type mockWalletRepo struct {
+	wallets []domain.Wallet
+}
+
+func (m *mockWalletRepo) FindForUpdate(ctx context.Context, userID uint, currency domain.Currency) (*domain.Wallet, error) {
+	for i := range m.wallets {
+		if m.wallets[i].UserID == userID && m.wallets[i].Balance.Currency == currency {
+			wallet := m.wallets[i]
+			return &wallet, nil
+		}
+	}
+
+	wallet := domain.NewWallet(userID, currency)
+	wallet.ID = uint(len(m.wallets) + 1)
+	m.wallets = append(m.wallets, *wallet)
+	return wallet, nil
+}
+func (m *mockWalletRepo) FindByUserID(ctx context.Context, userID uint) ([]domain.Wallet, error) {
+	var result []domain.Wallet
+	for _, w := range m.wallets {
+		if w.UserID == userID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+func (m *mockWalletRepo) Update(ctx context.Context, wallet *domain.Wallet) error {
+	for i := range m.wallets {
+		if m.wallets[i].ID == wallet.ID {
+			m.wallets[i] = *wallet
+		}
+	}
+	return nil
+}
+
+func setupWalletRouter(repo *mockWalletRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewWalletHandler(service.NewWalletService(repo), zap.NewNop())
+
+	r := newTestRouter()
+	r.GET("/users/:id/wallets", h.GetByUserID)
+
+	return r
+}
+
+func TestWalletHandler_GetByUserID(t *testing.T) {
+	repo := &mockWalletRepo{
+		wallets: []domain.Wallet{{ID: 1, UserID: 2, Balance: domain.Money{Minor: 700, Currency: domain.CurrencyUSD}}},
+	}
+	r := setupWalletRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/2/wallets", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data []struct {
+			Balance moneyBody
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, "7.00", body.Data[0].Balance.Amount)
+}
@@ -0,0 +1,42 @@
+// Package logging menyimpan logger request-scoped di context sehingga
+// handler, service dan repository menulis request_id yang sama di setiap
+// baris log tanpa harus meneruskan logger secara eksplisit.
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDField adalah nama field request ID di setiap baris log
+const RequestIDField = "request_id"
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithRequestID menyimpan request ID dan logger turunan base yang sudah
+// membawa field request_id di ctx
+func WithRequestID(ctx context.Context, base *zap.Logger, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return context.WithValue(ctx, loggerKey{}, base.With(zap.String(RequestIDField, id)))
+}
+
+// RequestID mengambil request ID dari ctx, kosong jika tidak ada
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext mengambil logger request-scoped dari ctx. fallback dipakai di
+// luar request HTTP (background job, test); nil berarti zap.NewNop.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	if fallback == nil {
+		return zap.NewNop()
+	}
+	return fallback
+}
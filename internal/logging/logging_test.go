@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := WithRequestID(context.Background(), zap.New(core), "req-1")
+
+	if got := RequestID(ctx); got != "req-1" {
+		t.Fatalf("expected request ID req-1, got %q", got)
+	}
+
+	FromContext(ctx, zap.NewNop()).Info("hello")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()[RequestIDField]; got != "req-1" {
+		t.Fatalf("expected request_id req-1, got %v", got)
+	}
+}
+
+func TestFromContext_Fallback(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	fallback := zap.New(core)
+
+	FromContext(context.Background(), fallback).Info("hello")
+	if logs.Len() != 1 {
+		t.Fatalf("expected fallback logger to be used, got %d entries", logs.Len())
+	}
+
+	if FromContext(context.Background(), nil) == nil {
+		t.Fatal("expected nop logger when fallback is nil")
+	}
+	if got := RequestID(context.Background()); got != "" {
+		t.Fatalf("expected empty request ID, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
+)
+
+// ActorHeader berisi identitas pemanggil untuk audit trail. Nilainya
+// dikirim client apa adanya sehingga tidak dipakai untuk otorisasi.
+const ActorHeader = "X-Actor"
+
+// AuthorizationHeader membawa token admin dalam bentuk "Bearer <token>"
+const AuthorizationHeader = "Authorization"
+
+// anonymousActor dipakai jika request tidak mengirim ActorHeader
+const anonymousActor = "anonymous"
+
+// Audit menyimpan actor dan request ID di context request sehingga setiap
+// mutasi transaksi tercatat lengkap di transaction_events. Request dengan
+// bearer token yang ada di adminTokens ditandai AuditInfo.Admin.
+func Audit(adminTokens []string) gin.HandlerFunc {
+	// simpan hash supaya perbandingan constant-time tidak bocor panjang token
+	hashes := make([][sha256.Size]byte, 0, len(adminTokens))
+	for _, token := range adminTokens {
+		hashes = append(hashes, sha256.Sum256([]byte(token)))
+	}
+
+	isAdmin := func(header string) bool {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return false
+		}
+
+		sum := sha256.Sum256([]byte(token))
+		match := 0
+		for i := range hashes {
+			match |= subtle.ConstantTimeCompare(sum[:], hashes[i][:])
+		}
+		return match == 1
+	}
+
+	return func(c *gin.Context) {
+		actor := c.GetHeader(ActorHeader)
+		if actor == "" {
+			actor = anonymousActor
+		}
+
+		// request ID dari middleware RequestID, header hanya jika Audit
+		// dipasang tanpa RequestID
+		requestID := logging.RequestID(c.Request.Context())
+		if requestID == "" {
+			requestID = c.GetHeader(RequestIDHeader)
+		}
+
+		ctx := domain.WithAuditInfo(c.Request.Context(), domain.AuditInfo{
+			Actor:     actor,
+			RequestID: requestID,
+			Admin:     isAdmin(c.GetHeader(AuthorizationHeader)),
+		})
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got domain.AuditInfo
+
+	r := gin.New()
+	r.Use(Audit([]string{"admin-secret"}))
+	r.GET("/", func(c *gin.Context) {
+		got = domain.AuditInfoFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(ActorHeader, "ops@example.com")
+	req.Header.Set(RequestIDHeader, "req-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Actor != "ops@example.com" || got.RequestID != "req-123" || got.Admin {
+		t.Fatalf("unexpected audit info: %+v", got)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got.Actor != anonymousActor || got.RequestID != "" || got.Admin {
+		t.Fatalf("expected anonymous actor, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(ActorHeader, "admin@example.com")
+	req.Header.Set(AuthorizationHeader, "Bearer admin-secret")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !got.Admin || got.Actor != "admin@example.com" {
+		t.Fatalf("expected admin actor, got %+v", got)
+	}
+
+	// Actor saja tidak cukup, admin hanya dari token yang valid
+	for _, auth := range []string{"", "Bearer wrong-secret", "admin-secret", "Bearer "} {
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(ActorHeader, "admin@example.com")
+		if auth != "" {
+			req.Header.Set(AuthorizationHeader, auth)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got.Admin {
+			t.Fatalf("authorization %q: expected non-admin, got %+v", auth, got)
+		}
+	}
+}
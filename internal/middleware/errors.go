@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/apierror"
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
+)
+
+// ProblemContentType adalah media type RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// internalErrorDetail menggantikan pesan error internal (GORM, driver
+// database) yang tidak boleh sampai ke client
+const internalErrorDetail = "internal server error"
+
+// Problem adalah body error RFC 7807 dengan extension code, errors dan
+// correlation_id
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code stabil untuk dibaca mesin, lihat package apierror
+	Code   string                `json:"code"`
+	Errors []apierror.FieldError `json:"errors,omitempty"`
+	// CorrelationID hanya diisi untuk error 5xx, dicocokkan dengan log server
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Errors menulis error terakhir di c.Errors sebagai application/problem+json
+// jika handler belum menulis response. Handler cukup memanggil c.Error(err)
+// lalu return; pemetaan status dan code ada di apierror.From.
+func Errors(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// handler yang sudah memilih response sendiri (termasuk c.Status tanpa
+		// body) hanya memakai c.Error untuk dicatat
+		if len(c.Errors) == 0 || c.Writer.Written() || c.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		apiErr := apierror.From(err)
+
+		problem := Problem{
+			Type:     "about:blank",
+			Title:    statusTitle(apiErr.Status),
+			Status:   apiErr.Status,
+			Detail:   apiErr.Detail,
+			Instance: c.Request.URL.Path,
+			Code:     apiErr.Code,
+			Errors:   apiErr.Fields,
+		}
+
+		if apiErr.Status >= http.StatusInternalServerError {
+			problem.CorrelationID = correlationID(c)
+			problem.Detail = internalErrorDetail
+			logging.FromContext(c.Request.Context(), logger).Error("request failed",
+				zap.String("correlation_id", problem.CorrelationID),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("status", apiErr.Status),
+				zap.Error(err),
+			)
+		}
+
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(apiErr.Status, problem)
+	}
+}
+
+func statusTitle(status int) string {
+	if status == apierror.StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// correlationID memakai request ID dari middleware RequestID atau audit info
+// jika ada, selain itu dibuat baru supaya error tetap bisa dilacak di log
+func correlationID(c *gin.Context) string {
+	if id := logging.RequestID(c.Request.Context()); id != "" {
+		return id
+	}
+	if id := domain.AuditInfoFromContext(c.Request.Context()).RequestID; id != "" {
+		return id
+	}
+	if id := c.GetHeader(RequestIDHeader); id != "" {
+		return id
+	}
+	return newRequestID()
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/apierror"
+	"transaction-technical-test/internal/domain"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Errors(zap.NewNop()))
+	r.GET("/not-found", func(c *gin.Context) {
+		_ = c.Error(domain.ErrTransactionNotFound)
+	})
+	r.GET("/validation", func(c *gin.Context) {
+		_ = c.Error(apierror.Validation(apierror.FieldError{Field: "status", Message: "is required"}))
+	})
+	r.GET("/internal", func(c *gin.Context) {
+		_ = c.Error(errors.New("dial tcp 10.0.0.5:3306: connect: connection refused"))
+	})
+	r.GET("/written", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.Status(http.StatusAccepted)
+	})
+
+	serve := func(path string, header map[string]string) (*httptest.ResponseRecorder, Problem) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var p Problem
+		if w.Body.Len() > 0 {
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatalf("invalid json: %v", err)
+			}
+		}
+		return w, p
+	}
+
+	t.Run("Domain Error", func(t *testing.T) {
+		w, p := serve("/not-found", nil)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != ProblemContentType {
+			t.Fatalf("expected %s, got %s", ProblemContentType, got)
+		}
+		if p.Code != "transaction_not_found" || p.Title != "Not Found" || p.Status != 404 || p.Instance != "/not-found" {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
+		if p.CorrelationID != "" {
+			t.Fatalf("expected no correlation id for 4xx, got %s", p.CorrelationID)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, p := serve("/validation", nil)
+
+		if p.Code != apierror.CodeValidationFailed || len(p.Errors) != 1 || p.Errors[0].Field != "status" {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
+	})
+
+	t.Run("Internal Error Is Generic", func(t *testing.T) {
+		w, p := serve("/internal", nil)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", w.Code)
+		}
+		if strings.Contains(w.Body.String(), "10.0.0.5") {
+			t.Fatalf("internal error leaked: %s", w.Body.String())
+		}
+		if p.Code != apierror.CodeInternal || p.Detail != internalErrorDetail || len(p.CorrelationID) != 32 {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
+	})
+
+	t.Run("Correlation ID From Request", func(t *testing.T) {
+		_, p := serve("/internal", map[string]string{RequestIDHeader: "req-42"})
+
+		if p.CorrelationID != "req-42" {
+			t.Fatalf("expected correlation id req-42, got %s", p.CorrelationID)
+		}
+	})
+
+	t.Run("Response Already Written", func(t *testing.T) {
+		w, _ := serve("/written", nil)
+
+		if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+			t.Fatalf("expected untouched 202, got %d %s", w.Code, w.Body.String())
+		}
+	})
+}
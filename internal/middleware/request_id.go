@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/logging"
+)
+
+// RequestIDHeader berisi ID request dari client atau proxy, dikembalikan di
+// response dan ditulis di setiap baris log
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength membatasi ID dari client supaya tidak membengkakkan log
+const maxRequestIDLength = 128
+
+// RequestID memakai X-Request-ID dari client jika valid, selain itu membuat
+// yang baru. ID dikirim balik di response dan logger turunan dengan field
+// request_id disimpan di context untuk dipakai lewat logging.FromContext.
+func RequestID(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), logger, id))
+		c.Next()
+	}
+}
+
+// validRequestID hanya menerima karakter yang aman ditulis ke log dan
+// header (huruf, angka, - _ . :)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
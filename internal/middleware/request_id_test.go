@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	var audit domain.AuditInfo
+
+	r := gin.New()
+	r.Use(RequestID(zap.New(core)), Audit(nil))
+	r.GET("/", func(c *gin.Context) {
+		audit = domain.AuditInfoFromContext(c.Request.Context())
+		logging.FromContext(c.Request.Context(), zap.NewNop()).Info("handled")
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"accepts client id", "req-123", true},
+		{"accepts uuid", "0b6f8a3e-5c1d-4e7a-9f2b-3d4c5e6f7a8b", true},
+		{"generates when missing", "", false},
+		{"replaces unsafe id", "req\n123", false},
+		{"replaces too long id", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.TakeAll()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.header {
+				t.Fatalf("expected request ID %q, got %q", tt.header, id)
+			}
+			if !tt.keep && (id == tt.header || !validRequestID(id)) {
+				t.Fatalf("expected generated request ID, got %q", id)
+			}
+
+			if audit.RequestID != id {
+				t.Fatalf("expected audit request ID %q, got %q", id, audit.RequestID)
+			}
+
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].ContextMap()[logging.RequestIDField] != id {
+				t.Fatalf("expected log line with request_id %q, got %+v", id, entries)
+			}
+		})
+	}
+}
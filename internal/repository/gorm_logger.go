@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"transaction-technical-test/internal/logging"
+)
+
+// GormLogger meneruskan log GORM ke zap. Logger diambil dari context query
+// (lihat dbFromContext) sehingga setiap baris SQL membawa request_id.
+type GormLogger struct {
+	base          *zap.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger mencatat query gagal dan query yang lebih lama dari
+// slowThreshold; 0 mematikan log slow query. Semua query baru dicatat
+// (level debug) setelah LogMode(logger.Info), misal lewat db.Debug().
+func NewGormLogger(base *zap.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		base:          base,
+		level:         gormlogger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Info {
+		l.logger(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Warn {
+		l.logger(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Error {
+		l.logger(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// ParamsFilter membuang nilai parameter sehingga SQL di log tetap berisi
+// placeholder dan data transaksi tidak ikut tercatat
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+// Trace dipanggil GORM setelah setiap query. ErrRecordNotFound tidak
+// dicatat karena repository memetakannya ke error domain (404), dan query
+// yang dibatalkan client hanya dicatat di level debug.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	canceled := errors.Is(err, context.Canceled)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !canceled
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	switch {
+	case canceled && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.logger(ctx).Debug("query canceled", queryFields(sql, rows, elapsed, zap.Error(err))...)
+	case failed && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.logger(ctx).Error("query failed", queryFields(sql, rows, elapsed, zap.Error(err))...)
+	case slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger(ctx).Warn("slow query", queryFields(sql, rows, elapsed, zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.logger(ctx).Debug("query", queryFields(sql, rows, elapsed)...)
+	}
+}
+
+func (l *GormLogger) logger(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, l.base)
+}
+
+func queryFields(sql string, rows int64, elapsed time.Duration, extra ...zap.Field) []zap.Field {
+	return append([]zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+	}, extra...)
+}
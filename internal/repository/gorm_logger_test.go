@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
+)
+
+func TestGormLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(core)
+
+	db := setupTestDB(t)
+	ctx := logging.WithRequestID(context.Background(), base, "req-7")
+
+	t.Run("Query Failed Carries Request ID", func(t *testing.T) {
+		logs.TakeAll()
+		// logger di context menang atas base milik GormLogger
+		session := db.Session(&gorm.Session{Logger: NewGormLogger(zap.NewNop(), 0)})
+
+		if err := session.WithContext(ctx).Exec("SELECT * FROM missing_table").Error; err == nil {
+			t.Fatalf("expected error from missing table")
+		}
+
+		entries := logs.FilterMessage("query failed").All()
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 query failed entry, got %d", len(entries))
+		}
+		if got := entries[0].ContextMap()[logging.RequestIDField]; got != "req-7" {
+			t.Fatalf("expected request_id req-7, got %v", got)
+		}
+	})
+
+	t.Run("Record Not Found Is Not Logged", func(t *testing.T) {
+		logs.TakeAll()
+		repo := NewTransactionRepository(db.Session(&gorm.Session{Logger: NewGormLogger(base, 0)}))
+
+		if _, err := repo.FindByID(ctx, 999); !errors.Is(err, domain.ErrTransactionNotFound) {
+			t.Fatalf("expected ErrTransactionNotFound, got %v", err)
+		}
+		if logs.Len() != 0 {
+			t.Fatalf("expected no log entries, got %+v", logs.All())
+		}
+	})
+
+	t.Run("Canceled Query Is Debug", func(t *testing.T) {
+		logs.TakeAll()
+		logger := NewGormLogger(base, 0)
+
+		logger.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 0 }, context.Canceled)
+
+		if logs.FilterLevelExact(zapcore.ErrorLevel).Len() != 0 {
+			t.Fatalf("expected no error entry for canceled query, got %+v", logs.All())
+		}
+		if logs.FilterMessage("query canceled").FilterLevelExact(zapcore.DebugLevel).Len() != 1 {
+			t.Fatalf("expected debug entry for canceled query, got %+v", logs.All())
+		}
+	})
+
+	t.Run("Parameters Are Not Logged", func(t *testing.T) {
+		logs.TakeAll()
+		session := db.Session(&gorm.Session{Logger: NewGormLogger(base, 0)})
+
+		err := session.WithContext(ctx).Exec("SELECT * FROM missing_table WHERE secret = ?", "s3cr3t-value").Error
+		if err == nil {
+			t.Fatalf("expected error from missing table")
+		}
+
+		entries := logs.FilterMessage("query failed").All()
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 query failed entry, got %d", len(entries))
+		}
+		sql, _ := entries[0].ContextMap()["sql"].(string)
+		if strings.Contains(sql, "s3cr3t-value") || !strings.Contains(sql, "?") {
+			t.Fatalf("expected placeholder without parameter value, got %q", sql)
+		}
+	})
+
+	t.Run("Slow Query", func(t *testing.T) {
+		logs.TakeAll()
+		logger := NewGormLogger(base, time.Millisecond)
+
+		logger.Trace(ctx, time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+		entries := logs.FilterMessage("slow query").All()
+		if len(entries) != 1 || entries[0].ContextMap()["sql"] != "SELECT 1" {
+			t.Fatalf("expected slow query entry, got %+v", logs.All())
+		}
+	})
+
+	t.Run("Info Level Logs Every Query", func(t *testing.T) {
+		logs.TakeAll()
+		logger := NewGormLogger(base, 0)
+
+		fc := func() (string, int64) { return "SELECT 1", 1 }
+		logger.Trace(ctx, time.Now(), fc, nil)
+		if logs.Len() != 0 {
+			t.Fatalf("expected no entry at warn level, got %+v", logs.All())
+		}
+
+		logger.LogMode(gormlogger.Info).Trace(ctx, time.Now(), fc, nil)
+		if logs.FilterMessage("query").Len() != 1 {
+			t.Fatalf("expected query entry at info level, got %+v", logs.All())
+		}
+
+		logger.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), fc, errors.New("db error"))
+		if logs.Len() != 1 {
+			t.Fatalf("expected silent logger to skip, got %+v", logs.All())
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type IdempotencyKeyModel struct {
+	Key          string `gorm:"column:idempotency_key;primaryKey;size:255"`
+	Fingerprint  string `gorm:"size:64;not null"`
+	StatusCode   int    `gorm:"not null;default:0"`
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
+
+// Mapper
+func idempotencyToDomain(m *IdempotencyKeyModel) domain.IdempotencyRecord {
+	return domain.IdempotencyRecord{
+		Key:          m.Key,
+		Fingerprint:  m.Fingerprint,
+		StatusCode:   m.StatusCode,
+		ResponseBody: m.ResponseBody,
+		CreatedAt:    m.CreatedAt,
+		ExpiresAt:    m.ExpiresAt,
+	}
+}
+
+func idempotencyFromDomain(d *domain.IdempotencyRecord) IdempotencyKeyModel {
+	return IdempotencyKeyModel{
+		Key:          d.Key,
+		Fingerprint:  d.Fingerprint,
+		StatusCode:   d.StatusCode,
+		ResponseBody: d.ResponseBody,
+		CreatedAt:    d.CreatedAt,
+		ExpiresAt:    d.ExpiresAt,
+	}
+}
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+// Constructor
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve memakai INSERT ... ON CONFLICT DO NOTHING (MySQL: ON DUPLICATE KEY)
+// sehingga dua request bersamaan dengan key yang sama tidak sama-sama lolos.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (bool, error) {
+	model := idempotencyFromDomain(rec)
+
+	result := dbFromContext(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *IdempotencyRepository) FindByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	var model IdempotencyKeyModel
+
+	if err := dbFromContext(ctx, r.db).Where("idempotency_key = ?", key).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrIdempotencyKeyNotFound
+		}
+		return nil, err
+	}
+
+	rec := idempotencyToDomain(&model)
+	return &rec, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	result := dbFromContext(ctx, r.db).Model(&IdempotencyKeyModel{}).
+		Where("idempotency_key = ?", key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": body,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	return dbFromContext(ctx, r.db).Where("idempotency_key = ?", key).Delete(&IdempotencyKeyModel{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("expires_at <= ?", now).Delete(&IdempotencyKeyModel{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func setupIdempotencyRepo(t *testing.T) *IdempotencyRepository {
+	t.Helper()
+
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&IdempotencyKeyModel{}); err != nil {
+		t.Fatalf("failed migrate: %v", err)
+	}
+
+	return NewIdempotencyRepository(db)
+}
+
+func TestIdempotencyRepository_ReserveAndComplete(t *testing.T) {
+	ctx := context.Background()
+	repo := setupIdempotencyRepo(t)
+	now := time.Now()
+
+	rec := &domain.IdempotencyRecord{
+		Key:         "abc",
+		Fingerprint: "fp",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	reserved, err := repo.Reserve(ctx, rec)
+	if err != nil || !reserved {
+		t.Fatalf("expected first reserve to succeed, got %v %v", reserved, err)
+	}
+
+	reserved, err = repo.Reserve(ctx, rec)
+	if err != nil || reserved {
+		t.Fatalf("expected duplicate reserve to be rejected, got %v %v", reserved, err)
+	}
+
+	if err := repo.Complete(ctx, "abc", 201, []byte(`{"data":1}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByKey(ctx, "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found.IsCompleted() || found.StatusCode != 201 || string(found.ResponseBody) != `{"data":1}` {
+		t.Fatalf("unexpected record: %+v", found)
+	}
+}
+
+func TestIdempotencyRepository_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := setupIdempotencyRepo(t)
+
+	if _, err := repo.FindByKey(ctx, "missing"); err != domain.ErrIdempotencyKeyNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if err := repo.Complete(ctx, "missing", 201, nil); err != domain.ErrIdempotencyKeyNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := setupIdempotencyRepo(t)
+	now := time.Now()
+
+	_, _ = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "old", Fingerprint: "fp", ExpiresAt: now.Add(-time.Minute)})
+	_, _ = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "new", Fingerprint: "fp", ExpiresAt: now.Add(time.Hour)})
+
+	purged, err := repo.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged, got %d", purged)
+	}
+
+	if _, err := repo.FindByKey(ctx, "new"); err != nil {
+		t.Fatalf("expected unexpired key to remain")
+	}
+}
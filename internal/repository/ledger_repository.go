@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type LedgerAccountModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Code      string `gorm:"size:100;uniqueIndex;not null"`
+	Type      string `gorm:"size:32;not null"`
+	Currency  string `gorm:"size:3;not null"`
+	CreatedAt time.Time
+}
+
+type JournalEntryModel struct {
+	ID            uint `gorm:"primaryKey"`
+	TransactionID uint `gorm:"index"`
+	Description   string
+	Postings      []PostingModel `gorm:"foreignKey:JournalEntryID"`
+	CreatedAt     time.Time
+}
+
+type PostingModel struct {
+	ID             uint   `gorm:"primaryKey"`
+	JournalEntryID uint   `gorm:"index;not null"`
+	AccountID      uint   `gorm:"index;not null"`
+	AmountMinor    int64  `gorm:"not null"`
+	Currency       string `gorm:"size:3;not null"`
+}
+
+// Mapper
+func accountToDomain(m *LedgerAccountModel) domain.Account {
+	return domain.Account{
+		ID:        m.ID,
+		Code:      m.Code,
+		Type:      domain.AccountType(m.Type),
+		Currency:  domain.Currency(m.Currency),
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func journalEntryFromDomain(d *domain.JournalEntry) JournalEntryModel {
+	postings := make([]PostingModel, 0, len(d.Postings))
+	for _, p := range d.Postings {
+		postings = append(postings, PostingModel{
+			AccountID:   p.AccountID,
+			AmountMinor: p.Amount.Minor,
+			Currency:    string(p.Amount.Currency),
+		})
+	}
+
+	return JournalEntryModel{
+		ID:            d.ID,
+		TransactionID: d.TransactionID,
+		Description:   d.Description,
+		Postings:      postings,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+// Constructor
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// FindOrCreateAccount aman dipanggil bersamaan: insert yang kalah karena
+// unique index diabaikan lalu akun yang sudah ada dibaca ulang.
+func (r *LedgerRepository) FindOrCreateAccount(
+	ctx context.Context,
+	code string,
+	accountType domain.AccountType,
+	currency domain.Currency,
+) (*domain.Account, error) {
+	db := dbFromContext(ctx, r.db)
+
+	model := LedgerAccountModel{
+		Code:     code,
+		Type:     string(accountType),
+		Currency: string(currency),
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model).Error; err != nil {
+		return nil, err
+	}
+
+	var existing LedgerAccountModel
+	if err := db.Where("code = ?", code).First(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	account := accountToDomain(&existing)
+	return &account, nil
+}
+
+func (r *LedgerRepository) FindAccountByID(ctx context.Context, id uint) (*domain.Account, error) {
+	var model LedgerAccountModel
+
+	if err := dbFromContext(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	account := accountToDomain(&model)
+	return &account, nil
+}
+
+func (r *LedgerRepository) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	model := journalEntryFromDomain(entry)
+
+	if err := dbFromContext(ctx, r.db).Create(&model).Error; err != nil {
+		return err
+	}
+
+	entry.ID = model.ID
+	for i := range entry.Postings {
+		entry.Postings[i].ID = model.Postings[i].ID
+		entry.Postings[i].JournalEntryID = model.ID
+	}
+
+	return nil
+}
+
+func (r *LedgerRepository) AccountBalance(ctx context.Context, accountID uint) (int64, error) {
+	var balance int64
+
+	err := dbFromContext(ctx, r.db).Model(&PostingModel{}).
+		Select("COALESCE(SUM(amount_minor), 0)").
+		Where("account_id = ?", accountID).
+		Scan(&balance).Error
+
+	return balance, err
+}
+
+func (r *LedgerRepository) PostingTotals(ctx context.Context) ([]domain.Money, error) {
+	var rows []currencyAggregate
+
+	err := dbFromContext(ctx, r.db).Model(&PostingModel{}).
+		Select("currency, COALESCE(SUM(amount_minor), 0) AS total, COUNT(*) AS count").
+		Group("currency").
+		Order("currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Money, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.Money{
+			Minor:    row.Total,
+			Currency: domain.Currency(row.Currency),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestLedgerRepository_FindOrCreateAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := NewLedgerRepository(setupTestDB(t))
+
+	code := domain.UserWalletAccountCode(1, domain.CurrencyIDR)
+
+	first, err := repo.FindOrCreateAccount(ctx, code, domain.AccountTypeUserWallet, domain.CurrencyIDR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := repo.FindOrCreateAccount(ctx, code, domain.AccountTypeUserWallet, domain.CurrencyIDR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.ID == 0 || first.ID != second.ID {
+		t.Fatalf("expected same account, got %d and %d", first.ID, second.ID)
+	}
+
+	if _, err := repo.FindAccountByID(ctx, 99); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Fatalf("expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestLedgerRepository_EntryBalances(t *testing.T) {
+	ctx := context.Background()
+	repo := NewLedgerRepository(setupTestDB(t))
+
+	wallet, _ := repo.FindOrCreateAccount(ctx, domain.UserWalletAccountCode(1, domain.CurrencyIDR), domain.AccountTypeUserWallet, domain.CurrencyIDR)
+	settlement, _ := repo.FindOrCreateAccount(ctx, domain.SettlementAccountCode(domain.CurrencyIDR), domain.AccountTypeSettlement, domain.CurrencyIDR)
+
+	for _, amount := range []int64{1000, 250} {
+		entry, err := domain.NewJournalEntry(1, "payment", []domain.Posting{
+			domain.Debit(wallet.ID, idr(amount)),
+			domain.Credit(settlement.ID, idr(amount)),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if entry.ID == 0 || entry.Postings[0].ID == 0 {
+			t.Fatalf("expected ids to be assigned: %+v", entry)
+		}
+	}
+
+	balance, err := repo.AccountBalance(ctx, wallet.ID)
+	if err != nil || balance != 1250 {
+		t.Fatalf("expected wallet balance 1250, got %d (%v)", balance, err)
+	}
+	balance, err = repo.AccountBalance(ctx, settlement.ID)
+	if err != nil || balance != -1250 {
+		t.Fatalf("expected settlement balance -1250, got %d (%v)", balance, err)
+	}
+
+	totals, err := repo.PostingTotals(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(totals) != 1 || totals[0] != idr(0) {
+		t.Fatalf("expected zero IDR total, got %+v", totals)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"math"
+
+	"gorm.io/gorm"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// legacyAmountColumn adalah kolom amount float lama sebelum memakai minor unit
+const legacyAmountColumn = "amount"
+
+// MigrateLegacyAmount memindahkan data dari kolom float `amount` lama ke
+// `amount_minor` lalu menghapus kolom lama. Baris lama tidak punya currency,
+// sehingga dianggap memakai domain.DefaultCurrency. Aman dipanggil berulang
+// kali: jika kolom lama sudah tidak ada, fungsi ini tidak melakukan apa-apa.
+// Harus dipanggil setelah AutoMigrate(&TransactionModel{}).
+func MigrateLegacyAmount(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&TransactionModel{}, legacyAmountColumn) {
+		return nil
+	}
+
+	exp, _ := domain.DefaultCurrency.Exponent()
+	scale := int64(math.Pow10(exp))
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&TransactionModel{}).
+			Where("(amount_minor IS NULL OR amount_minor = 0) AND amount IS NOT NULL").
+			Updates(map[string]interface{}{
+				"amount_minor": gorm.Expr("ROUND(amount * ?)", scale),
+				"currency":     string(domain.DefaultCurrency),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&TransactionModel{}, legacyAmountColumn)
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// legacyTransactionModel adalah bentuk tabel sebelum amount memakai minor unit
+type legacyTransactionModel struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint
+	Amount    float64
+	Status    string
+	CreatedAt time.Time
+}
+
+func (legacyTransactionModel) TableName() string { return "transaction_models" }
+
+func TestMigrateLegacyAmount(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed open db: %v", err)
+	}
+
+	// :memory: dibuat per koneksi, pastikan transaksi memakai koneksi yang sama
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&legacyTransactionModel{}); err != nil {
+		t.Fatalf("failed migrate legacy: %v", err)
+	}
+	db.Create(&legacyTransactionModel{UserID: 1, Amount: 1000.5, Status: "success"})
+	db.Create(&legacyTransactionModel{UserID: 2, Amount: 0.1 + 0.2, Status: "pending"})
+
+	if err := db.AutoMigrate(&TransactionModel{}); err != nil {
+		t.Fatalf("failed migrate: %v", err)
+	}
+	if err := MigrateLegacyAmount(db); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if db.Migrator().HasColumn(&TransactionModel{}, legacyAmountColumn) {
+		t.Fatalf("expected legacy amount column to be dropped")
+	}
+
+	repo := NewTransactionRepository(db)
+
+	first, err := repo.FindByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Amount != (domain.Money{Minor: 100050, Currency: domain.CurrencyIDR}) {
+		t.Fatalf("expected 1000.50 IDR, got %v", first.Amount)
+	}
+
+	second, err := repo.FindByID(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Amount.Minor != 30 {
+		t.Fatalf("expected 30 minor units, got %d", second.Amount.Minor)
+	}
+
+	// Dipanggil ulang tidak boleh error
+	if err := MigrateLegacyAmount(db); err != nil {
+		t.Fatalf("expected idempotent migration, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type RefundModel struct {
+	ID            uint `gorm:"primaryKey"`
+	TransactionID uint `gorm:"index;not null"`
+	AmountMinor   int64
+	Currency      string `gorm:"size:3;not null"`
+	Reason        string
+	CreatedAt     time.Time
+}
+
+// Mapper
+func refundToDomain(m *RefundModel) domain.Refund {
+	return domain.Refund{
+		ID:            m.ID,
+		TransactionID: m.TransactionID,
+		Amount: domain.Money{
+			Minor:    m.AmountMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		Reason:    m.Reason,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func refundFromDomain(d *domain.Refund) RefundModel {
+	return RefundModel{
+		ID:            d.ID,
+		TransactionID: d.TransactionID,
+		AmountMinor:   d.Amount.Minor,
+		Currency:      string(d.Amount.Currency),
+		Reason:        d.Reason,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+// Constructor
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	model := refundFromDomain(refund)
+
+	if err := dbFromContext(ctx, r.db).Create(&model).Error; err != nil {
+		return err
+	}
+
+	refund.ID = model.ID
+	return nil
+}
+
+func (r *RefundRepository) FindByTransactionID(ctx context.Context, transactionID uint) ([]domain.Refund, error) {
+	var models []RefundModel
+
+	err := dbFromContext(ctx, r.db).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at asc, id asc").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Refund, 0, len(models))
+	for _, m := range models {
+		result = append(result, refundToDomain(&m))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestRefundRepository_CreateAndFind(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewRefundRepository(db)
+
+	first := domain.NewRefund(1, idr(300), "first")
+	second := domain.NewRefund(1, idr(200), "second")
+	other := domain.NewRefund(2, idr(100), "other")
+
+	for _, r := range []*domain.Refund{first, second, other} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	refunds, err := repo.FindByTransactionID(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(refunds) != 2 {
+		t.Fatalf("expected 2 refunds, got %d", len(refunds))
+	}
+	if refunds[0].ID != first.ID || refunds[0].Amount != idr(300) || refunds[1].Reason != "second" {
+		t.Fatalf("unexpected refunds: %+v", refunds)
+	}
+}
+
+func TestTransactor_RollbackOnError(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	transactor := NewTransactor(db)
+	txRepo := NewTransactionRepository(db)
+	refundRepo := NewRefundRepository(db)
+
+	tx := &domain.Transaction{UserID: 1, Amount: idr(1000), Refunded: idr(0), Status: domain.StatusSuccess}
+	_ = txRepo.Create(ctx, tx)
+
+	errBoom := errors.New("boom")
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := txRepo.FindByIDForUpdate(ctx, tx.ID)
+		if err != nil {
+			return err
+		}
+		if err := locked.Refund(idr(500)); err != nil {
+			return err
+		}
+		if err := refundRepo.Create(ctx, domain.NewRefund(tx.ID, idr(500), "")); err != nil {
+			return err
+		}
+		if err := txRepo.Update(ctx, locked); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+
+	found, _ := txRepo.FindByID(ctx, tx.ID)
+	if found.Status != domain.StatusSuccess || found.Refunded.Minor != 0 {
+		t.Fatalf("expected transaction unchanged after rollback, got %+v", found)
+	}
+
+	refunds, _ := refundRepo.FindByTransactionID(ctx, tx.ID)
+	if len(refunds) != 0 {
+		t.Fatalf("expected no refunds after rollback, got %d", len(refunds))
+	}
+}
+
+func TestTransactor_Commit(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	transactor := NewTransactor(db)
+	txRepo := NewTransactionRepository(db)
+
+	tx := &domain.Transaction{UserID: 1, Amount: idr(1000), Refunded: idr(0), Status: domain.StatusSuccess}
+	_ = txRepo.Create(ctx, tx)
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := txRepo.FindByIDForUpdate(ctx, tx.ID)
+		if err != nil {
+			return err
+		}
+		if err := locked.Refund(idr(1000)); err != nil {
+			return err
+		}
+		// Nested WithinTransaction memakai transaction yang sama
+		return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return txRepo.Update(ctx, locked)
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, _ := txRepo.FindByID(ctx, tx.ID)
+	if found.Status != domain.StatusRefunded || found.Refunded != idr(1000) {
+		t.Fatalf("expected refunded transaction, got %+v", found)
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// TransactionEventModel adalah audit trail append-only, baris tidak pernah
+// di-update atau dihapus
+type TransactionEventModel struct {
+	ID             uint   `gorm:"primaryKey"`
+	TransactionID  uint   `gorm:"index;not null"`
+	Type           string `gorm:"size:16;not null"`
+	OldStatus      string `gorm:"size:32"`
+	NewStatus      string `gorm:"size:32"`
+	OldAmountMinor int64  `gorm:"not null;default:0"`
+	NewAmountMinor int64  `gorm:"not null;default:0"`
+	Currency       string `gorm:"size:3;not null"`
+	Actor          string `gorm:"size:100;not null"`
+	RequestID      string `gorm:"size:100"`
+	CreatedAt      time.Time
+}
+
+func (TransactionEventModel) TableName() string {
+	return "transaction_events"
+}
+
+// Mapper
+func eventToDomain(m *TransactionEventModel) domain.TransactionEvent {
+	return domain.TransactionEvent{
+		ID:            m.ID,
+		TransactionID: m.TransactionID,
+		Type:          domain.TransactionEventType(m.Type),
+		OldStatus:     domain.TransactionStatus(m.OldStatus),
+		NewStatus:     domain.TransactionStatus(m.NewStatus),
+		OldAmount: domain.Money{
+			Minor:    m.OldAmountMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		NewAmount: domain.Money{
+			Minor:    m.NewAmountMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		Actor:     m.Actor,
+		RequestID: m.RequestID,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// newEvent membuat event dari kondisi sebelum (old) dan sesudah (new)
+// mutasi; old nil untuk created dan new nil untuk deleted.
+func newEvent(
+	ctx context.Context,
+	eventType domain.TransactionEventType,
+	old *TransactionModel,
+	new *TransactionModel,
+) TransactionEventModel {
+	audit := domain.AuditInfoFromContext(ctx)
+
+	event := TransactionEventModel{
+		Type:      string(eventType),
+		Actor:     audit.Actor,
+		RequestID: audit.RequestID,
+		CreatedAt: time.Now(),
+	}
+	if old != nil {
+		event.TransactionID = old.ID
+		event.OldStatus = old.Status
+		event.OldAmountMinor = old.AmountMinor
+		event.Currency = old.Currency
+	}
+	if new != nil {
+		event.TransactionID = new.ID
+		event.NewStatus = new.Status
+		event.NewAmountMinor = new.AmountMinor
+		event.Currency = new.Currency
+	}
+
+	return event
+}
+
+// appendEvent menulis event memakai db yang sama dengan mutasinya
+func appendEvent(db *gorm.DB, event TransactionEventModel) error {
+	return db.Create(&event).Error
+}
+
+// History mengambil audit trail transaksi urut dari yang paling lama.
+// Tetap tersedia walaupun transaksinya sudah dihapus. Transaksi lama yang
+// dibuat sebelum audit trail ada mengembalikan list kosong, bukan not found.
+func (r *TransactionRepository) History(ctx context.Context, transactionID uint) ([]domain.TransactionEvent, error) {
+	var models []TransactionEventModel
+
+	err := dbFromContext(ctx, r.db).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at, id").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(models) == 0 {
+		if _, err := findModel(dbFromContext(ctx, r.db).Unscoped(), transactionID); err != nil {
+			return nil, err
+		}
+		return []domain.TransactionEvent{}, nil
+	}
+
+	events := make([]domain.TransactionEvent, 0, len(models))
+	for i := range models {
+		events = append(events, eventToDomain(&models[i]))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestTransactionRepository_History(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTransactionRepository(db)
+	ctx := domain.WithAuditInfo(context.Background(), domain.AuditInfo{
+		Actor:     "ops@example.com",
+		RequestID: "req-1",
+	})
+
+	tx := domain.NewTransaction(1, idr(1000))
+	if err := repo.Create(ctx, tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = tx.UpdateStatus(domain.StatusSuccess)
+	if err := repo.Update(ctx, tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := repo.Delete(context.Background(), tx.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events, err := repo.History(ctx, tx.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	created, updated, deleted := events[0], events[1], events[2]
+
+	if created.Type != domain.EventTransactionCreated || created.NewStatus != domain.StatusPending ||
+		created.NewAmount != idr(1000) || created.Actor != "ops@example.com" || created.RequestID != "req-1" {
+		t.Fatalf("unexpected created event: %+v", created)
+	}
+	if updated.Type != domain.EventTransactionUpdated ||
+		updated.OldStatus != domain.StatusPending || updated.NewStatus != domain.StatusSuccess {
+		t.Fatalf("unexpected updated event: %+v", updated)
+	}
+	if deleted.Type != domain.EventTransactionDeleted || deleted.OldStatus != domain.StatusSuccess ||
+		deleted.NewStatus != "" || deleted.Actor != domain.SystemActor {
+		t.Fatalf("unexpected deleted event: %+v", deleted)
+	}
+
+	if _, err := repo.History(ctx, 99); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound, got %v", err)
+	}
+}
+
+func TestTransactionRepository_HistoryWithoutEvents(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	tx := domain.NewTransaction(1, idr(1000))
+	if err := repo.Create(ctx, tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulasikan transaksi lama yang dibuat sebelum audit trail ada
+	db.Where("transaction_id = ?", tx.ID).Delete(&TransactionEventModel{})
+
+	events, err := repo.History(ctx, tx.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events == nil || len(events) != 0 {
+		t.Fatalf("expected empty history, got %+v", events)
+	}
+}
+
+func TestTransactionRepository_HistoryRolledBackWithMutation(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewTransactionRepository(db)
+	transactor := NewTransactor(db)
+
+	tx := domain.NewTransaction(1, idr(1000))
+	_ = repo.Create(ctx, tx)
+
+	errBoom := errors.New("boom")
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_ = tx.UpdateStatus(domain.StatusFailed)
+		if err := repo.Update(ctx, tx); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected errBoom, got %v", err)
+	}
+
+	events, err := repo.History(ctx, tx.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != domain.EventTransactionCreated {
+		t.Fatalf("expected only created event, got %+v", events)
+	}
+}
@@ -1,66 +1,163 @@
 package repository
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"transaction-technical-test/internal/domain"
 )
 
 type TransactionModel struct {
-	ID        uint `gorm:"primaryKey"`
-	UserID    uint
-	Amount    float64
-	Status    string
-	CreatedAt time.Time
+	ID                     uint `gorm:"primaryKey"`
+	UserID                 uint
+	AmountMinor            int64  `gorm:"not null;default:0"`
+	RefundedMinor          int64  `gorm:"not null;default:0"`
+	AuthorizedMinor        int64  `gorm:"not null;default:0"`
+	Currency               string `gorm:"size:3;not null;default:IDR"`
+	Status                 string
+	AuthorizationExpiresAt *time.Time `gorm:"index"`
+	Version                uint       `gorm:"not null;default:1"`
+	CreatedAt              time.Time
+	DeletedAt              gorm.DeletedAt `gorm:"index"`
 }
 
 // Mapper
 func toDomain(m *TransactionModel) domain.Transaction {
 	return domain.Transaction{
-		ID:        m.ID,
-		UserID:    m.UserID,
-		Amount:    m.Amount,
-		Status:    domain.TransactionStatus(m.Status),
-		CreatedAt: m.CreatedAt,
+		ID:     m.ID,
+		UserID: m.UserID,
+		Amount: domain.Money{
+			Minor:    m.AmountMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		Refunded: domain.Money{
+			Minor:    m.RefundedMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		Authorized: domain.Money{
+			Minor:    m.AuthorizedMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		AuthorizationExpiresAt: m.AuthorizationExpiresAt,
+		Status:                 domain.TransactionStatus(m.Status),
+		Version:                m.Version,
+		CreatedAt:              m.CreatedAt,
+		DeletedAt:              deletedAtToDomain(m.DeletedAt),
 	}
 }
 
+func deletedAtToDomain(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
+
 func fromDomain(d *domain.Transaction) TransactionModel {
 	return TransactionModel{
-		ID:        d.ID,
-		UserID:    d.UserID,
-		Amount:    d.Amount,
-		Status:    string(d.Status),
-		CreatedAt: d.CreatedAt,
+		ID:                     d.ID,
+		UserID:                 d.UserID,
+		AmountMinor:            d.Amount.Minor,
+		RefundedMinor:          d.Refunded.Minor,
+		AuthorizedMinor:        d.Authorized.Minor,
+		Currency:               string(d.Amount.Currency),
+		Status:                 string(d.Status),
+		AuthorizationExpiresAt: d.AuthorizationExpiresAt,
+		Version:                d.Version,
+		CreatedAt:              d.CreatedAt,
+		DeletedAt:              deletedAtFromDomain(d.DeletedAt),
+	}
+}
+
+func deletedAtFromDomain(t *time.Time) gorm.DeletedAt {
+	if t == nil {
+		return gorm.DeletedAt{}
 	}
+	return gorm.DeletedAt{Time: *t, Valid: true}
+}
+
+// settledStatuses adalah status transaksi yang dihitung di dashboard.
+// Amount dihitung bersih setelah dikurangi refund; transaksi yang sudah
+// refund penuh bernilai nol sehingga tidak ikut dihitung.
+var settledStatuses = []string{
+	string(domain.StatusSuccess),
+	string(domain.StatusCaptured),
+	string(domain.StatusPartiallyRefunded),
+}
+
+// currencyAggregate adalah hasil agregasi amount per mata uang
+type currencyAggregate struct {
+	Currency string
+	Total    int64
+	Count    int64
 }
 
 type TransactionRepository struct {
 	db *gorm.DB
+	// now adalah clock untuk menghitung batas hari, diganti di test
+	now func() time.Time
 }
 
 // Constructor
 func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+	return &TransactionRepository{
+		db:  db,
+		now: time.Now,
+	}
 }
 
 // Implement
-func (r *TransactionRepository) Create(tx *domain.Transaction) error {
+// Create, Update dan Delete menulis transaction_events di database
+// transaction yang sama dengan mutasinya.
+func (r *TransactionRepository) Create(ctx context.Context, tx *domain.Transaction) error {
 	model := fromDomain(tx)
+	if model.Version == 0 {
+		model.Version = 1
+	}
 
-	if err := r.db.Create(&model).Error; err != nil {
+	err := dbFromContext(ctx, r.db).Transaction(func(db *gorm.DB) error {
+		if err := db.Create(&model).Error; err != nil {
+			return err
+		}
+
+		return appendEvent(db, newEvent(ctx, domain.EventTransactionCreated, nil, &model))
+	})
+	if err != nil {
 		return err
 	}
 
 	tx.ID = model.ID
+	tx.Version = model.Version
 	return nil
 }
-func (r *TransactionRepository) FindByID(id uint) (*domain.Transaction, error) {
+func (r *TransactionRepository) FindByID(ctx context.Context, id uint) (*domain.Transaction, error) {
 	var model TransactionModel
 
-	if err := r.db.First(&model, id).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	tx := toDomain(&model)
+	return &tx, nil
+}
+
+// FindByIDForUpdate memakai SELECT ... FOR UPDATE di MySQL. SQLite tidak
+// punya row lock, tapi write transaction di SQLite sudah saling serial.
+func (r *TransactionRepository) FindByIDForUpdate(ctx context.Context, id uint) (*domain.Transaction, error) {
+	var model TransactionModel
+
+	err := dbFromContext(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&model, id).Error
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrTransactionNotFound
 		}
@@ -70,36 +167,82 @@ func (r *TransactionRepository) FindByID(id uint) (*domain.Transaction, error) {
 	tx := toDomain(&model)
 	return &tx, nil
 }
-func (r *TransactionRepository) FindAll(filter domain.TransactionFilter) ([]domain.Transaction, error) {
-	var models []TransactionModel
 
-	query := r.db.Model(&TransactionModel{})
+// filterQuery menerapkan kondisi filter tanpa Limit, Offset dan Cursor,
+// dipakai bersama oleh FindAll dan Count
+func (r *TransactionRepository) filterQuery(ctx context.Context, filter domain.TransactionFilter) *gorm.DB {
+	query := dbFromContext(ctx, r.db).Model(&TransactionModel{})
 
-	if filter.UserID != nil {
-		query = query.Where("user_id = ?", *filter.UserID)
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
 	}
 
-	if filter.Status != nil {
-		query = query.Where("status = ?", string(*filter.Status))
+	if len(filter.UserIDs) > 0 {
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			statuses[i] = string(s)
+		}
+		query = query.Where("status IN ?", statuses)
 	}
 
 	if filter.From != nil {
-		query = query.Where("created_at >= ?", *filter.From)
+		query = query.Where("created_at >= ?", filter.From.UTC())
 	}
 
 	if filter.To != nil {
-		query = query.Where("created_at <= ?", *filter.To)
+		if filter.ToExclusive {
+			query = query.Where("created_at < ?", filter.To.UTC())
+		} else {
+			query = query.Where("created_at <= ?", filter.To.UTC())
+		}
 	}
 
+	if filter.MinAmount != nil {
+		query = query.Where("currency = ? AND amount_minor >= ?", string(filter.MinAmount.Currency), filter.MinAmount.Minor)
+	}
+
+	if filter.MaxAmount != nil {
+		query = query.Where("currency = ? AND amount_minor <= ?", string(filter.MaxAmount.Currency), filter.MaxAmount.Minor)
+	}
+
+	return query
+}
+
+func (r *TransactionRepository) FindAll(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	var models []TransactionModel
+
+	query := r.filterQuery(ctx, filter)
+
 	if filter.Limit > 0 {
 		query = query.Limit(filter.Limit)
 	}
 
-	if filter.Offset >= 0 {
-		query = query.Offset(filter.Offset)
+	sort := filter.Sort
+	if len(sort) == 0 {
+		sort = domain.DefaultSort
+	}
+
+	// halaman sebelumnya diambil dengan urutan terbalik lalu dibalik lagi
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	order, err := orderClause(sort, backward)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor == nil {
+		if filter.Offset >= 0 {
+			query = query.Offset(filter.Offset)
+		}
+	} else {
+		condition, args := keysetCondition(sort, filter.Cursor)
+		query = query.Where(condition, args...)
 	}
 
-	if err := query.Order("created_at desc").Find(&models).Error; err != nil {
+	if err := query.Order(order).Find(&models).Error; err != nil {
 		return nil, err
 	}
 
@@ -109,62 +252,322 @@ func (r *TransactionRepository) FindAll(filter domain.TransactionFilter) ([]doma
 		result = append(result, tx)
 	}
 
+	if filter.Cursor != nil && filter.Cursor.Backward {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+
 	return result, nil
 }
 
-func (r *TransactionRepository) Update(tx *domain.Transaction) error {
-	result := r.db.Model(&TransactionModel{}).
-		Where("id = ?", tx.ID).
-		Updates(map[string]interface{}{
-			"status": tx.Status,
-			"amount": tx.Amount,
-		})
+// sortColumns adalah whitelist kolom ?sort=, nama kolom di ORDER BY tidak
+// pernah berasal langsung dari input
+var sortColumns = map[domain.SortField]string{
+	domain.SortAmount:    "amount_minor",
+	domain.SortCurrency:  "currency",
+	domain.SortCreatedAt: "created_at",
+	domain.SortUserID:    "user_id",
+	domain.SortStatus:    "status",
+	domain.SortID:        "id",
+}
 
-	if result.RowsAffected == 0 {
-		return domain.ErrTransactionNotFound
+// orderClause membangun ORDER BY dari sort, dengan arah dibalik jika reverse
+func orderClause(sort domain.Sort, reverse bool) (string, error) {
+	parts := make([]string, len(sort))
+	for i, key := range sort {
+		column, ok := sortColumns[key.Field]
+		if !ok {
+			return "", domain.ErrInvalidSort
+		}
+
+		direction := "asc"
+		if key.Desc != reverse {
+			direction = "desc"
+		}
+		parts[i] = column + " " + direction
 	}
+	return strings.Join(parts, ", "), nil
+}
 
-	return result.Error
+// keysetCondition membangun kondisi "setelah cursor" untuk sort dengan arah
+// campuran: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., dengan < untuk field
+// descending. Arah dibalik untuk cursor backward. Kolom sudah divalidasi
+// orderClause.
+func keysetCondition(sort domain.Sort, c *domain.Cursor) (string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	for i, key := range sort {
+		var conditions []string
+		for _, prev := range sort[:i] {
+			conditions = append(conditions, sortColumns[prev.Field]+" = ?")
+			args = append(args, c.Value(prev.Field))
+		}
+
+		op := ">"
+		if key.Desc != c.Backward {
+			op = "<"
+		}
+		conditions = append(conditions, sortColumns[key.Field]+" "+op+" ?")
+		args = append(args, c.Value(key.Field))
+
+		clauses = append(clauses, "("+strings.Join(conditions, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
 }
 
-func (r *TransactionRepository) Delete(id uint) error {
-	result := r.db.Delete(&TransactionModel{}, id)
+func (r *TransactionRepository) Count(ctx context.Context, filter domain.TransactionFilter) (int64, error) {
+	var count int64
+	if err := r.filterQuery(ctx, filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
 
-	if result.RowsAffected == 0 {
-		return domain.ErrTransactionNotFound
+func (r *TransactionRepository) Update(ctx context.Context, tx *domain.Transaction) error {
+	err := dbFromContext(ctx, r.db).Transaction(func(db *gorm.DB) error {
+		old, err := findModel(db, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		if old.Version != tx.Version {
+			return domain.ErrConcurrentModification
+		}
+
+		// version di WHERE tetap diperlukan karena baris lama tidak dikunci
+		result := db.Model(&TransactionModel{}).
+			Where("id = ? AND version = ?", tx.ID, tx.Version).
+			Updates(map[string]interface{}{
+				"status":                   tx.Status,
+				"amount_minor":             tx.Amount.Minor,
+				"refunded_minor":           tx.Refunded.Minor,
+				"authorized_minor":         tx.Authorized.Minor,
+				"currency":                 string(tx.Amount.Currency),
+				"authorization_expires_at": tx.AuthorizationExpiresAt,
+				"version":                  gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return domain.ErrConcurrentModification
+		}
+
+		updated := fromDomain(tx)
+		updated.Version++
+		return appendEvent(db, newEvent(ctx, domain.EventTransactionUpdated, old, &updated))
+	})
+	if err != nil {
+		return err
 	}
 
-	return result.Error
+	tx.Version++
+	return nil
+}
+
+// Delete hanya mengisi deleted_at. Transaksi yang dihapus tidak muncul di
+// query biasa maupun agregat dashboard, tapi masih bisa di-Restore.
+// Authorization yang masih aktif ditolak karena worker expiry tidak melihat
+// baris yang dihapus, sehingga hold di wallet tidak akan pernah dilepas.
+func (r *TransactionRepository) Delete(ctx context.Context, id uint) error {
+	return dbFromContext(ctx, r.db).Transaction(func(db *gorm.DB) error {
+		old, err := findModel(db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+
+		if domain.TransactionStatus(old.Status) == domain.StatusAuthorized {
+			return domain.ErrAuthorizationActive
+		}
+
+		if err := db.Delete(&TransactionModel{}, id).Error; err != nil {
+			return err
+		}
+
+		return appendEvent(db, newEvent(ctx, domain.EventTransactionDeleted, old, nil))
+	})
+}
+
+func (r *TransactionRepository) Restore(ctx context.Context, id uint) error {
+	return dbFromContext(ctx, r.db).Transaction(func(db *gorm.DB) error {
+		old, err := findModel(db.Unscoped(), id)
+		if err != nil {
+			return err
+		}
+
+		if !old.DeletedAt.Valid {
+			return domain.ErrNotDeleted
+		}
+
+		err = db.Unscoped().Model(&TransactionModel{}).
+			Where("id = ?", id).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		restored := *old
+		restored.DeletedAt = gorm.DeletedAt{}
+		return appendEvent(db, newEvent(ctx, domain.EventTransactionRestored, old, &restored))
+	})
 }
-func (r *TransactionRepository) TotalSuccessToday() (float64, error) {
-	var total float64
 
-	start := time.Now().Truncate(24 * time.Hour)
-	end := start.Add(24 * time.Hour)
+// PurgeDeleted menghapus permanen transaksi yang sudah di-soft delete
+// sebelum before. Event purged tetap ditulis ke audit trail.
+func (r *TransactionRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := dbFromContext(ctx, r.db).Transaction(func(db *gorm.DB) error {
+		var models []TransactionModel
+
+		err := db.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Find(&models).Error
+		if err != nil || len(models) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(models))
+		for i := range models {
+			ids = append(ids, models[i].ID)
+			if err := appendEvent(db, newEvent(ctx, domain.EventTransactionPurged, &models[i], nil)); err != nil {
+				return err
+			}
+		}
+
+		result := db.Unscoped().Delete(&TransactionModel{}, ids)
+		if result.Error != nil {
+			return result.Error
+		}
 
-	err := r.db.Model(&TransactionModel{}).
-		Select("COALESCE(SUM(amount), 0)").
-		Where("status = ?", string(domain.StatusSuccess)).
+		purged = result.RowsAffected
+		return nil
+	})
+
+	return purged, err
+}
+
+// findModel membaca kondisi transaksi sebelum dimutasi untuk audit trail
+func findModel(db *gorm.DB, id uint) (*TransactionModel, error) {
+	var model TransactionModel
+
+	if err := db.First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+func (r *TransactionRepository) FindExpiredAuthorizations(ctx context.Context, now time.Time, limit int) ([]domain.Transaction, error) {
+	var models []TransactionModel
+
+	err := dbFromContext(ctx, r.db).
+		Where("status = ? AND authorization_expires_at <= ?", string(domain.StatusAuthorized), now).
+		Order("authorization_expires_at").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Transaction, 0, len(models))
+	for _, m := range models {
+		result = append(result, toDomain(&m))
+	}
+
+	return result, nil
+}
+func (r *TransactionRepository) TotalSuccessToday(ctx context.Context, loc *time.Location) ([]domain.Money, error) {
+	var rows []currencyAggregate
+
+	start, end := dayWindow(r.now(), loc)
+
+	err := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select("currency, COALESCE(SUM(amount_minor - refunded_minor), 0) AS total, COUNT(*) AS count").
+		Where("status IN ?", settledStatuses).
 		Where("created_at >= ? AND created_at < ?", start, end).
-		Scan(&total).Error
+		Group("currency").
+		Order("currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
-	return total, err
+	result := make([]domain.Money, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.Money{
+			Minor:    row.Total,
+			Currency: domain.Currency(row.Currency),
+		})
+	}
+
+	return result, nil
+}
+
+// AverageTransactionAmount dihitung dari SUM/COUNT lalu dibulatkan ke minor unit
+// terdekat di Go, supaya hasilnya sama antara MySQL dan SQLite.
+func (r *TransactionRepository) AverageTransactionAmount(ctx context.Context) ([]domain.Money, error) {
+	var rows []currencyAggregate
+
+	err := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select("currency, COALESCE(SUM(amount_minor - refunded_minor), 0) AS total, COUNT(*) AS count").
+		Where("status IN ?", settledStatuses).
+		Group("currency").
+		Order("currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Money, 0, len(rows))
+	for _, row := range rows {
+		total := domain.Money{Minor: row.Total, Currency: domain.Currency(row.Currency)}
+		result = append(result, total.DivRound(row.Count))
+	}
+
+	return result, nil
 }
-func (r *TransactionRepository) AverageAmountPerUser() (float64, error) {
-	var avg float64
 
-	err := r.db.Model(&TransactionModel{}).
-		Select("COALESCE(AVG(amount), 0)").
-		Where("status = ?", string(domain.StatusSuccess)).
-		Scan(&avg).Error
+// AverageTotalPerUser menjumlahkan amount per user di subquery, lalu
+// merata-ratakan total tersebut per mata uang
+func (r *TransactionRepository) AverageTotalPerUser(ctx context.Context) ([]domain.Money, error) {
+	db := dbFromContext(ctx, r.db)
+
+	perUser := db.Model(&TransactionModel{}).
+		Select("user_id, currency, SUM(amount_minor - refunded_minor) AS total").
+		Where("status IN ?", settledStatuses).
+		Group("user_id, currency")
+
+	var rows []currencyAggregate
+	err := db.Table("(?) AS per_user", perUser).
+		Select("currency, COALESCE(SUM(total), 0) AS total, COUNT(*) AS count").
+		Group("currency").
+		Order("currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Money, 0, len(rows))
+	for _, row := range rows {
+		total := domain.Money{Minor: row.Total, Currency: domain.Currency(row.Currency)}
+		result = append(result, total.DivRound(row.Count))
+	}
 
-	return avg, err
+	return result, nil
 }
 
-func (r *TransactionRepository) Latest(limit int) ([]domain.Transaction, error) {
+func (r *TransactionRepository) Latest(ctx context.Context, limit int) ([]domain.Transaction, error) {
 	var models []TransactionModel
 
-	if err := r.db.
+	if err := dbFromContext(ctx, r.db).
 		Order("created_at desc").
 		Limit(limit).
 		Find(&models).Error; err != nil {
@@ -179,3 +582,356 @@ func (r *TransactionRepository) Latest(limit int) ([]domain.Transaction, error)
 
 	return result, nil
 }
+
+// bucketAggregate adalah hasil agregasi per bucket dan mata uang
+type bucketAggregate struct {
+	Bucket   int
+	Currency string
+	Total    int64
+	Count    int64
+}
+
+// VolumeByBucket memakai CASE WHEN berantai (bukan fungsi tanggal) supaya
+// query yang sama jalan di MySQL dan SQLite, dan batas bucket yang dihitung
+// di Go tetap mengikuti zona waktu pemanggil.
+func (r *TransactionRepository) VolumeByBucket(
+	ctx context.Context,
+	boundaries []time.Time,
+	status *domain.TransactionStatus,
+) ([]domain.BucketVolume, error) {
+	if len(boundaries) < 2 {
+		return []domain.BucketVolume{}, nil
+	}
+
+	bucketExpr, args := bucketCase(boundaries)
+
+	query := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select(bucketExpr+", currency, COALESCE(SUM(amount_minor - refunded_minor), 0) AS total, COUNT(*) AS count", args...).
+		Where("created_at >= ? AND created_at < ?", boundaries[0].UTC(), boundaries[len(boundaries)-1].UTC())
+
+	if status != nil {
+		query = query.Where("status = ?", string(*status))
+	} else {
+		query = query.Where("status IN ?", settledStatuses)
+	}
+
+	var rows []bucketAggregate
+	if err := query.Group("bucket, currency").Order("bucket, currency").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.BucketVolume, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.BucketVolume{
+			Bucket: row.Bucket,
+			Count:  row.Count,
+			Sum: domain.Money{
+				Minor:    row.Total,
+				Currency: domain.Currency(row.Currency),
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// bucketCase membuat ekspresi "CASE WHEN created_at < ? THEN i ... END AS
+// bucket" untuk batas bucket yang urut naik. Baris di luar
+// [boundaries[0], boundaries[n]) harus difilter pemanggil.
+func bucketCase(boundaries []time.Time) (string, []interface{}) {
+	ends := make([]interface{}, 0, len(boundaries)-1)
+	for _, end := range boundaries[1:] {
+		ends = append(ends, end.UTC())
+	}
+	return caseExpr("created_at", ends)
+}
+
+// caseExpr adalah bentuk umum bucketCase untuk kolom apa pun; ends adalah
+// batas atas eksklusif setiap bucket
+func caseExpr(column string, ends []interface{}) (string, []interface{}) {
+	var expr strings.Builder
+
+	expr.WriteString("CASE")
+	for i := range ends {
+		expr.WriteString(" WHEN " + column + " < ? THEN " + strconv.Itoa(i))
+	}
+	expr.WriteString(" END AS bucket")
+
+	return expr.String(), ends
+}
+
+// bucketStatusAggregate adalah hasil agregasi per bucket, status dan mata uang
+type bucketStatusAggregate struct {
+	Bucket   int
+	Status   string
+	Currency string
+	Count    int64
+	Total    int64
+	Net      int64
+	Largest  int64
+}
+
+// StatusVolumeByBucket mengambil agregat per status untuk beberapa periode
+// sekaligus dalam satu query GROUP BY, memakai bucketCase yang sama dengan
+// VolumeByBucket.
+func (r *TransactionRepository) StatusVolumeByBucket(ctx context.Context, boundaries []time.Time) ([]domain.BucketStatusVolume, error) {
+	if len(boundaries) < 2 {
+		return []domain.BucketStatusVolume{}, nil
+	}
+
+	bucketExpr, args := bucketCase(boundaries)
+
+	var rows []bucketStatusAggregate
+	err := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select(bucketExpr+", status, currency, COUNT(*) AS count, "+
+			"COALESCE(SUM(amount_minor), 0) AS total, "+
+			"COALESCE(SUM(amount_minor - refunded_minor), 0) AS net, "+
+			"COALESCE(MAX(amount_minor), 0) AS largest", args...).
+		Where("created_at >= ? AND created_at < ?", boundaries[0].UTC(), boundaries[len(boundaries)-1].UTC()).
+		Group("bucket, status, currency").
+		Order("bucket, status, currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.BucketStatusVolume, 0, len(rows))
+	for _, row := range rows {
+		currency := domain.Currency(row.Currency)
+		result = append(result, domain.BucketStatusVolume{
+			Bucket: row.Bucket,
+			StatusVolume: domain.StatusVolume{
+				Status:  domain.TransactionStatus(row.Status),
+				Count:   row.Count,
+				Sum:     domain.Money{Minor: row.Total, Currency: currency},
+				Net:     domain.Money{Minor: row.Net, Currency: currency},
+				Largest: domain.Money{Minor: row.Largest, Currency: currency},
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// amountQuery menerapkan AmountFilter. Distribusi memakai amount kotor
+// (ticket size), bukan amount setelah refund.
+func (r *TransactionRepository) amountQuery(ctx context.Context, filter domain.AmountFilter) *gorm.DB {
+	query := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Where("currency = ?", string(filter.Currency)).
+		Where("created_at >= ? AND created_at < ?", filter.From.UTC(), filter.To.UTC())
+
+	if filter.Status != nil {
+		return query.Where("status = ?", string(*filter.Status))
+	}
+	return query.Where("status IN ?", settledStatuses)
+}
+
+func (r *TransactionRepository) AmountSummary(ctx context.Context, filter domain.AmountFilter) (domain.AmountSummary, error) {
+	var row struct {
+		Count int64
+		Min   int64
+		Max   int64
+	}
+
+	err := r.amountQuery(ctx, filter).
+		Select("COUNT(*) AS count, COALESCE(MIN(amount_minor), 0) AS min, COALESCE(MAX(amount_minor), 0) AS max").
+		Scan(&row).Error
+	if err != nil {
+		return domain.AmountSummary{}, err
+	}
+
+	return domain.AmountSummary{
+		Count: row.Count,
+		Min:   domain.Money{Minor: row.Min, Currency: filter.Currency},
+		Max:   domain.Money{Minor: row.Max, Currency: filter.Currency},
+	}, nil
+}
+
+// AmountsAtRanks memakai ROW_NUMBER() karena MySQL dan SQLite tidak punya
+// fungsi persentil yang sama. Semua rank diambil dari satu urutan amount,
+// jadi data hanya dipindai sekali berapa pun jumlah persentilnya.
+func (r *TransactionRepository) AmountsAtRanks(ctx context.Context, filter domain.AmountFilter, ranks []int64) ([]domain.Money, error) {
+	result := make([]domain.Money, len(ranks))
+	for i := range result {
+		result[i] = domain.Money{Currency: filter.Currency}
+	}
+	if len(ranks) == 0 {
+		return result, nil
+	}
+
+	ranked := r.amountQuery(ctx, filter).
+		Select("amount_minor, ROW_NUMBER() OVER (ORDER BY amount_minor) AS rn")
+
+	var rows []struct {
+		Rn          int64
+		AmountMinor int64
+	}
+	err := dbFromContext(ctx, r.db).
+		Table("(?) AS ranked", ranked).
+		Select("rn, amount_minor").
+		Where("rn IN ?", ranks).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byRank := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		byRank[row.Rn] = row.AmountMinor
+	}
+	for i, rank := range ranks {
+		if amount, ok := byRank[rank]; ok {
+			result[i].Minor = amount
+		}
+	}
+
+	return result, nil
+}
+
+func (r *TransactionRepository) AmountHistogram(
+	ctx context.Context,
+	filter domain.AmountFilter,
+	boundaries []int64,
+) ([]domain.HistogramCount, error) {
+	if len(boundaries) < 2 {
+		return []domain.HistogramCount{}, nil
+	}
+
+	ends := make([]interface{}, 0, len(boundaries)-1)
+	for _, end := range boundaries[1:] {
+		ends = append(ends, end)
+	}
+	bucketExpr, args := caseExpr("amount_minor", ends)
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	err := r.amountQuery(ctx, filter).
+		Select(bucketExpr+", COUNT(*) AS count", args...).
+		Where("amount_minor >= ? AND amount_minor < ?", boundaries[0], boundaries[len(boundaries)-1]).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.HistogramCount, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.HistogramCount{Bucket: row.Bucket, Count: row.Count})
+	}
+
+	return result, nil
+}
+
+func (r *TransactionRepository) TopUsers(ctx context.Context, filter domain.TopUsersFilter) ([]domain.UserVolume, error) {
+	order := "total DESC, user_id"
+	if filter.By == domain.RankByCount {
+		order = "count DESC, user_id"
+	}
+
+	var rows []struct {
+		UserID uint
+		Count  int64
+		Total  int64
+	}
+	err := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select("user_id, COUNT(*) AS count, COALESCE(SUM(amount_minor - refunded_minor), 0) AS total").
+		Where("status IN ?", settledStatuses).
+		Where("currency = ?", string(filter.Currency)).
+		Where("created_at >= ? AND created_at < ?", filter.From.UTC(), filter.To.UTC()).
+		Group("user_id").
+		Order(order).
+		Limit(filter.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.UserVolume, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.UserVolume{
+			UserID: row.UserID,
+			Count:  row.Count,
+			Volume: domain.Money{Minor: row.Total, Currency: filter.Currency},
+		})
+	}
+
+	return result, nil
+}
+
+// statusAggregate adalah hasil agregasi per status dan mata uang
+type statusAggregate struct {
+	Status   string
+	Currency string
+	Count    int64
+	Total    int64
+	Net      int64
+	Largest  int64
+}
+
+func (r *TransactionRepository) UserVolumeByStatus(ctx context.Context, userID uint) ([]domain.StatusVolume, error) {
+	var rows []statusAggregate
+
+	err := dbFromContext(ctx, r.db).Model(&TransactionModel{}).
+		Select("status, currency, COUNT(*) AS count, "+
+			"COALESCE(SUM(amount_minor), 0) AS total, "+
+			"COALESCE(SUM(amount_minor - refunded_minor), 0) AS net, "+
+			"COALESCE(MAX(amount_minor), 0) AS largest").
+		Where("user_id = ?", userID).
+		Group("status, currency").
+		Order("status, currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.StatusVolume, 0, len(rows))
+	for _, row := range rows {
+		currency := domain.Currency(row.Currency)
+		result = append(result, domain.StatusVolume{
+			Status:  domain.TransactionStatus(row.Status),
+			Count:   row.Count,
+			Sum:     domain.Money{Minor: row.Total, Currency: currency},
+			Net:     domain.Money{Minor: row.Net, Currency: currency},
+			Largest: domain.Money{Minor: row.Largest, Currency: currency},
+		})
+	}
+
+	return result, nil
+}
+
+// UserActivity memakai ORDER BY + LIMIT, bukan MIN/MAX, karena SQLite
+// mengembalikan hasil agregat created_at sebagai string
+func (r *TransactionRepository) UserActivity(ctx context.Context, userID uint) (*time.Time, *time.Time, error) {
+	db := dbFromContext(ctx, r.db)
+
+	var first []TransactionModel
+	if err := db.Select("created_at").Where("user_id = ?", userID).
+		Order("created_at asc").Limit(1).Find(&first).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(first) == 0 {
+		return nil, nil, nil
+	}
+
+	var last []TransactionModel
+	if err := db.Select("created_at").Where("user_id = ?", userID).
+		Order("created_at desc").Limit(1).Find(&last).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(last) == 0 {
+		return &first[0].CreatedAt, &first[0].CreatedAt, nil
+	}
+
+	return &first[0].CreatedAt, &last[0].CreatedAt, nil
+}
+
+// dayWindow mengembalikan awal dan akhir hari now di zona waktu loc dalam UTC.
+// AddDate dipakai (bukan +24 jam) supaya hari dengan pergantian DST tetap benar.
+func dayWindow(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	y, m, d := now.In(loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	return start.UTC(), start.AddDate(0, 0, 1).UTC()
+}
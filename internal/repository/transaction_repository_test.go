@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -10,7 +14,7 @@ import (
 	"transaction-technical-test/internal/domain"
 )
 
-func setupTestRepo(t *testing.T) *TransactionRepository {
+func setupTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
@@ -18,25 +22,76 @@ func setupTestRepo(t *testing.T) *TransactionRepository {
 		t.Fatalf("failed open db: %v", err)
 	}
 
-	if err := db.AutoMigrate(&TransactionModel{}); err != nil {
+	// :memory: dibuat per koneksi, pastikan transaction memakai koneksi yang sama
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	migrateTestDB(t, db)
+
+	db.Exec("DELETE FROM transaction_models")
+
+	return db
+}
+
+// setupFileTestDB membuka SQLite berbasis file dengan banyak koneksi supaya
+// transaction dari goroutine berbeda benar-benar berjalan bersamaan. WAL dan
+// busy_timeout membuat writer kedua menunggu lock, bukan langsung gagal.
+func setupFileTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed open db: %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(8)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	migrateTestDB(t, db)
+
+	return db
+}
+
+func migrateTestDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	err := db.AutoMigrate(
+		&TransactionModel{},
+		&TransactionEventModel{},
+		&RefundModel{},
+		&LedgerAccountModel{},
+		&JournalEntryModel{},
+		&PostingModel{},
+		&WalletModel{},
+	)
+	if err != nil {
 		t.Fatalf("failed migrate: %v", err)
 	}
+}
 
-	db.Exec("DELETE FROM transaction_models")
+func setupTestRepo(t *testing.T) *TransactionRepository {
+	t.Helper()
+
+	return NewTransactionRepository(setupTestDB(t))
+}
 
-	return NewTransactionRepository(db)
+func idr(minor int64) domain.Money {
+	return domain.Money{Minor: minor, Currency: domain.CurrencyIDR}
 }
 
 func TestTransactionRepository_Create(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	tx := &domain.Transaction{
 		UserID: 1,
-		Amount: 1000,
+		Amount: idr(1000),
 		Status: domain.StatusPending,
 	}
 
-	if err := repo.Create(tx); err != nil {
+	if err := repo.Create(ctx, tx); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -44,27 +99,28 @@ func TestTransactionRepository_Create(t *testing.T) {
 		t.Fatalf("expected id to be set")
 	}
 
-	found, err := repo.FindByID(tx.ID)
+	found, err := repo.FindByID(ctx, tx.ID)
 	if err != nil {
 		t.Fatalf("failed find after create")
 	}
 
-	if found.Amount != 1000 || found.Status != domain.StatusPending {
+	if found.Amount.Minor != 1000 || found.Status != domain.StatusPending {
 		t.Fatalf("data mismatch after create")
 	}
 }
 
 func TestTransactionRepository_FindByID_Success(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	tx := &domain.Transaction{
 		UserID: 1,
-		Amount: 500,
+		Amount: idr(500),
 		Status: domain.StatusSuccess,
 	}
-	_ = repo.Create(tx)
+	_ = repo.Create(ctx, tx)
 
-	found, err := repo.FindByID(tx.ID)
+	found, err := repo.FindByID(ctx, tx.ID)
 	if err != nil {
 		t.Fatalf("unexpected error")
 	}
@@ -72,36 +128,36 @@ func TestTransactionRepository_FindByID_Success(t *testing.T) {
 	if found.ID != tx.ID {
 		t.Fatalf("wrong id")
 	}
-	if found.Amount != 500 {
+	if found.Amount.Minor != 500 {
 		t.Fatalf("wrong amount")
 	}
 }
 
 func TestTransactionRepository_FindAll_Filter(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 	now := time.Now()
 
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID:    1,
-		Amount:    1000,
+		Amount:    idr(1000),
 		Status:    domain.StatusSuccess,
 		CreatedAt: now,
 	})
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID:    2,
-		Amount:    2000,
+		Amount:    idr(2000),
 		Status:    domain.StatusPending,
 		CreatedAt: now,
 	})
 
-	status := domain.StatusSuccess
 	filter := domain.TransactionFilter{
-		Status: &status,
-		Limit:  10,
-		Offset: 0,
+		Statuses: []domain.TransactionStatus{domain.StatusSuccess},
+		Limit:    10,
+		Offset:   0,
 	}
 
-	result, err := repo.FindAll(filter)
+	result, err := repo.FindAll(ctx, filter)
 	if err != nil {
 		t.Fatalf("unexpected error")
 	}
@@ -113,29 +169,30 @@ func TestTransactionRepository_FindAll_Filter(t *testing.T) {
 	if result[0].Status != domain.StatusSuccess {
 		t.Fatalf("wrong status")
 	}
-	if result[0].Amount != 1000 {
+	if result[0].Amount.Minor != 1000 {
 		t.Fatalf("wrong amount")
 	}
 }
 
 func TestTransactionRepository_Update_Success(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	tx := &domain.Transaction{
 		UserID: 1,
-		Amount: 1000,
+		Amount: idr(1000),
 		Status: domain.StatusPending,
 	}
-	_ = repo.Create(tx)
+	_ = repo.Create(ctx, tx)
 
 	tx.Status = domain.StatusSuccess
-	tx.Amount = 2000
+	tx.Amount = idr(2000)
 
-	if err := repo.Update(tx); err != nil {
+	if err := repo.Update(ctx, tx); err != nil {
 		t.Fatalf("unexpected error")
 	}
 
-	updated, err := repo.FindByID(tx.ID)
+	updated, err := repo.FindByID(ctx, tx.ID)
 	if err != nil {
 		t.Fatalf("failed find after update")
 	}
@@ -143,12 +200,13 @@ func TestTransactionRepository_Update_Success(t *testing.T) {
 	if updated.Status != domain.StatusSuccess {
 		t.Fatalf("status not updated")
 	}
-	if updated.Amount != 2000 {
+	if updated.Amount.Minor != 2000 {
 		t.Fatalf("amount not updated")
 	}
 }
 
 func TestTransactionRepository_Update_NotFound(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	tx := &domain.Transaction{
@@ -156,110 +214,142 @@ func TestTransactionRepository_Update_NotFound(t *testing.T) {
 		Status: domain.StatusSuccess,
 	}
 
-	err := repo.Update(tx)
+	err := repo.Update(ctx, tx)
 	if err != domain.ErrTransactionNotFound {
 		t.Fatalf("expected not found error")
 	}
 }
 
 func TestTransactionRepository_Delete_Success(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	tx := &domain.Transaction{
 		UserID: 1,
-		Amount: 100,
+		Amount: idr(100),
 		Status: domain.StatusPending,
 	}
-	_ = repo.Create(tx)
+	_ = repo.Create(ctx, tx)
 
-	if err := repo.Delete(tx.ID); err != nil {
+	if err := repo.Delete(ctx, tx.ID); err != nil {
 		t.Fatalf("unexpected error")
 	}
 
-	_, err := repo.FindByID(tx.ID)
+	_, err := repo.FindByID(ctx, tx.ID)
 	if err != domain.ErrTransactionNotFound {
 		t.Fatalf("expected not found after delete")
 	}
 }
 
+func TestTransactionRepository_Delete_ActiveAuthorization(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	expiresAt := time.Now()
+	tx := &domain.Transaction{
+		UserID:                 1,
+		Amount:                 idr(100),
+		Status:                 domain.StatusAuthorized,
+		AuthorizationExpiresAt: &expiresAt,
+	}
+	_ = repo.Create(ctx, tx)
+
+	if err := repo.Delete(ctx, tx.ID); !errors.Is(err, domain.ErrAuthorizationActive) {
+		t.Fatalf("expected ErrAuthorizationActive, got %v", err)
+	}
+
+	// Tetap terlihat oleh worker expiry supaya hold dilepas
+	expired, err := repo.FindExpiredAuthorizations(ctx, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != tx.ID {
+		t.Fatalf("expected authorization to stay visible, got %+v", expired)
+	}
+}
+
 func TestTransactionRepository_Delete_NotFound(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
-	err := repo.Delete(999)
+	err := repo.Delete(ctx, 999)
 	if err != domain.ErrTransactionNotFound {
 		t.Fatalf("expected not found error")
 	}
 }
 
 func TestTransactionRepository_TotalSuccessToday(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
 	startOfDay := time.Now().Truncate(24 * time.Hour)
 
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID:    1,
-		Amount:    1000,
+		Amount:    idr(1000),
 		Status:    domain.StatusSuccess,
 		CreatedAt: startOfDay.Add(1 * time.Hour),
 	})
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID:    2,
-		Amount:    999,
+		Amount:    idr(999),
 		Status:    domain.StatusSuccess,
 		CreatedAt: startOfDay.Add(-24 * time.Hour),
 	})
 
-	total, err := repo.TotalSuccessToday()
+	total, err := repo.TotalSuccessToday(ctx, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error")
 	}
 
-	if total < 999.99 || total > 1000.01 {
-		t.Fatalf("expected total ~1000, got %.2f", total)
+	if len(total) != 1 || total[0] != idr(1000) {
+		t.Fatalf("expected total 1000 IDR, got %v", total)
 	}
 
 }
 
-func TestTransactionRepository_AverageAmountPerUser(t *testing.T) {
+func TestTransactionRepository_AverageTransactionAmount(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID: 1,
-		Amount: 1000,
+		Amount: idr(1000),
 		Status: domain.StatusSuccess,
 	})
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID: 1,
-		Amount: 3000,
+		Amount: idr(3000),
 		Status: domain.StatusSuccess,
 	})
 
-	avg, err := repo.AverageAmountPerUser()
+	avg, err := repo.AverageTransactionAmount(ctx)
 	if err != nil {
 		t.Fatalf("unexpected error")
 	}
 
-	if avg < 1999.99 || avg > 2000.01 {
-		t.Fatalf("expected avg ~2000, got %.2f", avg)
+	if len(avg) != 1 || avg[0] != idr(2000) {
+		t.Fatalf("expected avg 2000 IDR, got %v", avg)
 	}
 
 }
 
 func TestTransactionRepository_Latest(t *testing.T) {
+	ctx := context.Background()
 	repo := setupTestRepo(t)
 
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID: 1,
-		Amount: 1000,
+		Amount: idr(1000),
 		Status: domain.StatusSuccess,
 	})
-	_ = repo.Create(&domain.Transaction{
+	_ = repo.Create(ctx, &domain.Transaction{
 		UserID: 2,
-		Amount: 2000,
+		Amount: idr(2000),
 		Status: domain.StatusPending,
 	})
 
-	result, err := repo.Latest(1)
+	result, err := repo.Latest(ctx, 1)
 	if err != nil {
 		t.Fatalf("unexpected error")
 	}
@@ -268,7 +358,776 @@ func TestTransactionRepository_Latest(t *testing.T) {
 		t.Fatalf("expected 1 result")
 	}
 
-	if result[0].Amount != 2000 {
+	if result[0].Amount.Minor != 2000 {
 		t.Fatalf("expected latest transaction")
 	}
 }
+
+func TestTransactionRepository_AverageTransactionAmount_PerCurrency(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(101), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID: 2,
+		Amount: domain.Money{Minor: 500, Currency: domain.CurrencyUSD},
+		Status: domain.StatusSuccess,
+	})
+
+	avg, err := repo.AverageTransactionAmount(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.Money{idr(101), {Minor: 500, Currency: domain.CurrencyUSD}}
+	if len(avg) != len(want) || avg[0] != want[0] || avg[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, avg)
+	}
+}
+
+func TestTransactionRepository_CanceledContext(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.FindAll(ctx, domain.TransactionFilter{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	err = repo.Delete(ctx, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from Delete, got %v", err)
+	}
+}
+
+func TestTransactionRepository_DashboardNetOfRefunds(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID:    1,
+		Amount:    idr(1000),
+		Refunded:  idr(400),
+		Status:    domain.StatusPartiallyRefunded,
+		CreatedAt: time.Now(),
+	})
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID:    2,
+		Amount:    idr(2000),
+		Refunded:  idr(2000),
+		Status:    domain.StatusRefunded,
+		CreatedAt: time.Now(),
+	})
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID:    3,
+		Amount:    idr(400),
+		Status:    domain.StatusSuccess,
+		CreatedAt: time.Now(),
+	})
+
+	total, err := repo.TotalSuccessToday(ctx, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(total) != 1 || total[0] != idr(1000) {
+		t.Fatalf("expected net total 1000 IDR, got %v", total)
+	}
+
+	avg, err := repo.AverageTransactionAmount(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(avg) != 1 || avg[0] != idr(500) {
+		t.Fatalf("expected net avg 500 IDR, got %v", avg)
+	}
+}
+
+func TestTransactionRepository_FindExpiredAuthorizations(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	now := time.Now()
+	expired := domain.NewTransaction(1, idr(1000))
+	_ = expired.Authorize(now.Add(-time.Minute))
+	active := domain.NewTransaction(1, idr(1000))
+	_ = active.Authorize(now.Add(time.Hour))
+	pending := domain.NewTransaction(1, idr(1000))
+
+	for _, tx := range []*domain.Transaction{expired, active, pending} {
+		if err := repo.Create(ctx, tx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	result, err := repo.FindExpiredAuthorizations(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].ID != expired.ID {
+		t.Fatalf("expected only expired authorization, got %+v", result)
+	}
+	if result[0].Authorized != idr(1000) || result[0].AuthorizationExpiresAt == nil {
+		t.Fatalf("expected authorization fields to round-trip, got %+v", result[0])
+	}
+
+	// Setelah di-void tidak lagi dianggap expired
+	voided := result[0]
+	_ = voided.Void()
+	if err := repo.Update(ctx, &voided); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err = repo.FindExpiredAuthorizations(ctx, now, 10)
+	if err != nil || len(result) != 0 {
+		t.Fatalf("expected no expired authorizations, got %d (%v)", len(result), err)
+	}
+}
+
+func TestTransactionRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	kept := &domain.Transaction{UserID: 1, Amount: idr(1000), Status: domain.StatusSuccess, CreatedAt: time.Now()}
+	deleted := &domain.Transaction{UserID: 2, Amount: idr(5000), Status: domain.StatusSuccess, CreatedAt: time.Now()}
+	_ = repo.Create(ctx, kept)
+	_ = repo.Create(ctx, deleted)
+
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := repo.FindByID(ctx, deleted.ID); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Fatalf("expected deleted transaction to be hidden, got %v", err)
+	}
+	if err := repo.Delete(ctx, deleted.ID); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Fatalf("expected second delete to be not found, got %v", err)
+	}
+
+	all, _ := repo.FindAll(ctx, domain.TransactionFilter{})
+	if len(all) != 1 || all[0].ID != kept.ID {
+		t.Fatalf("expected only kept transaction, got %+v", all)
+	}
+
+	latest, _ := repo.Latest(ctx, 10)
+	if len(latest) != 1 {
+		t.Fatalf("expected deleted transaction excluded from latest, got %d", len(latest))
+	}
+
+	total, _ := repo.TotalSuccessToday(ctx, time.UTC)
+	if len(total) != 1 || total[0] != idr(1000) {
+		t.Fatalf("expected deleted transaction excluded from total, got %v", total)
+	}
+
+	withDeleted, _ := repo.FindAll(ctx, domain.TransactionFilter{IncludeDeleted: true})
+	if len(withDeleted) != 2 {
+		t.Fatalf("expected 2 transactions with include_deleted, got %d", len(withDeleted))
+	}
+	for _, tx := range withDeleted {
+		if (tx.ID == deleted.ID) != (tx.DeletedAt != nil) {
+			t.Fatalf("unexpected DeletedAt on %d: %v", tx.ID, tx.DeletedAt)
+		}
+	}
+}
+
+func TestTransactionRepository_Restore(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	tx := domain.NewTransaction(1, idr(1000))
+	_ = repo.Create(ctx, tx)
+
+	if err := repo.Restore(ctx, tx.ID); !errors.Is(err, domain.ErrNotDeleted) {
+		t.Fatalf("expected ErrNotDeleted, got %v", err)
+	}
+	if err := repo.Restore(ctx, 99); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound, got %v", err)
+	}
+
+	_ = repo.Delete(ctx, tx.ID)
+	if err := repo.Restore(ctx, tx.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored, err := repo.FindByID(ctx, tx.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("expected restored transaction, got %+v (%v)", restored, err)
+	}
+
+	events, _ := repo.History(ctx, tx.ID)
+	if len(events) != 3 || events[2].Type != domain.EventTransactionRestored {
+		t.Fatalf("expected restored event, got %+v", events)
+	}
+}
+
+func TestTransactionRepository_PurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	old := domain.NewTransaction(1, idr(1000))
+	recent := domain.NewTransaction(1, idr(1000))
+	active := domain.NewTransaction(1, idr(1000))
+	for _, tx := range []*domain.Transaction{old, recent, active} {
+		_ = repo.Create(ctx, tx)
+	}
+	_ = repo.Delete(ctx, old.ID)
+	_ = repo.Delete(ctx, recent.ID)
+
+	// Mundurkan deleted_at supaya melewati retention
+	db.Unscoped().Model(&TransactionModel{}).
+		Where("id = ?", old.ID).
+		Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged, got %d", purged)
+	}
+
+	if err := repo.Restore(ctx, old.ID); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Fatalf("expected purged transaction to be gone, got %v", err)
+	}
+	if err := repo.Restore(ctx, recent.ID); err != nil {
+		t.Fatalf("expected recent deletion to be restorable, got %v", err)
+	}
+
+	// Audit trail tetap ada setelah purge
+	events, _ := repo.History(ctx, old.ID)
+	if len(events) != 3 || events[2].Type != domain.EventTransactionPurged {
+		t.Fatalf("expected purged event, got %+v", events)
+	}
+}
+
+func TestTransactionRepository_Update_OptimisticConcurrency(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	tx := domain.NewTransaction(1, idr(1000))
+	_ = repo.Create(ctx, tx)
+
+	first, _ := repo.FindByID(ctx, tx.ID)
+	second, _ := repo.FindByID(ctx, tx.ID)
+	if first.Version != 1 {
+		t.Fatalf("expected version 1, got %d", first.Version)
+	}
+
+	_ = first.UpdateStatus(domain.StatusSuccess)
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Version != 2 {
+		t.Fatalf("expected version 2 after update, got %d", first.Version)
+	}
+
+	// second masih memegang versi lama, tidak boleh menimpa perubahan first
+	_ = second.UpdateStatus(domain.StatusFailed)
+	if err := repo.Update(ctx, second); !errors.Is(err, domain.ErrConcurrentModification) {
+		t.Fatalf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	stored, _ := repo.FindByID(ctx, tx.ID)
+	if stored.Status != domain.StatusSuccess || stored.Version != 2 {
+		t.Fatalf("expected first write to win, got %+v", stored)
+	}
+}
+
+func TestTransactionRepository_TotalSuccessToday_Timezone(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	// 20:00 UTC tanggal 1 = 03:00 WIB tanggal 2
+	repo.now = func() time.Time { return time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC) }
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	// 01:00 WIB tanggal 2
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(1000), Status: domain.StatusSuccess,
+		CreatedAt: time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)})
+	// 17:00 WIB tanggal 1
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(200), Status: domain.StatusSuccess,
+		CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)})
+
+	total, err := repo.TotalSuccessToday(ctx, jakarta)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(total) != 1 || total[0] != idr(1000) {
+		t.Fatalf("expected only WIB-today transaction, got %v", total)
+	}
+
+	total, _ = repo.TotalSuccessToday(ctx, time.UTC)
+	if len(total) != 1 || total[0] != idr(1200) {
+		t.Fatalf("expected both transactions for UTC day, got %v", total)
+	}
+}
+
+func TestTransactionRepository_VolumeByBucket(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	usd := domain.Money{Minor: 500, Currency: domain.CurrencyUSD}
+
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(1000), Status: domain.StatusSuccess, CreatedAt: day(1, 9)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(3000), Status: domain.StatusSuccess, CreatedAt: day(1, 23)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: usd, Status: domain.StatusSuccess, CreatedAt: day(1, 12)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(7000), Status: domain.StatusPending, CreatedAt: day(3, 1)})
+	// di luar rentang
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(9000), Status: domain.StatusSuccess, CreatedAt: day(5, 1)})
+
+	boundaries := []time.Time{day(1, 0), day(2, 0), day(3, 0), day(4, 0)}
+
+	volumes, err := repo.VolumeByBucket(ctx, boundaries, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.BucketVolume{
+		{Bucket: 0, Count: 2, Sum: idr(4000)},
+		{Bucket: 0, Count: 1, Sum: usd},
+	}
+	if len(volumes) != len(want) {
+		t.Fatalf("expected %d volumes, got %+v", len(want), volumes)
+	}
+	for i := range want {
+		if volumes[i] != want[i] {
+			t.Fatalf("volume %d: expected %+v, got %+v", i, want[i], volumes[i])
+		}
+	}
+
+	pending := domain.StatusPending
+	volumes, err = repo.VolumeByBucket(ctx, boundaries, &pending)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(volumes) != 1 || volumes[0].Bucket != 2 || volumes[0].Sum != idr(7000) {
+		t.Fatalf("expected pending volume in bucket 2, got %+v", volumes)
+	}
+}
+
+func TestTransactionRepository_AverageTotalPerUser(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	// user 1 total 300, user 2 total 100: rata-rata per transaksi 133,
+	// rata-rata per user 200
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(200), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 3, Amount: idr(900), Status: domain.StatusFailed})
+
+	avg, err := repo.AverageTotalPerUser(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(avg) != 1 || avg[0] != idr(200) {
+		t.Fatalf("expected [%v], got %v", idr(200), avg)
+	}
+}
+
+func TestTransactionRepository_UserStats(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess, CreatedAt: last})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(300), Status: domain.StatusSuccess, CreatedAt: first})
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID: 1, Amount: idr(500), Refunded: idr(200), Status: domain.StatusPartiallyRefunded, CreatedAt: first,
+	})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(999), Status: domain.StatusSuccess})
+
+	volumes, err := repo.UserVolumeByStatus(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.StatusVolume{
+		{Status: domain.StatusPartiallyRefunded, Count: 1, Sum: idr(500), Net: idr(300), Largest: idr(500)},
+		{Status: domain.StatusSuccess, Count: 2, Sum: idr(400), Net: idr(400), Largest: idr(300)},
+	}
+	if len(volumes) != len(want) || volumes[0] != want[0] || volumes[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, volumes)
+	}
+
+	gotFirst, gotLast, err := repo.UserActivity(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFirst == nil || !gotFirst.Equal(first) || gotLast == nil || !gotLast.Equal(last) {
+		t.Fatalf("expected %s - %s, got %v - %v", first, last, gotFirst, gotLast)
+	}
+
+	gotFirst, gotLast, err = repo.UserActivity(ctx, 99)
+	if err != nil || gotFirst != nil || gotLast != nil {
+		t.Fatalf("expected no activity, got %v - %v (%v)", gotFirst, gotLast, err)
+	}
+}
+
+func TestTransactionRepository_StatusVolumeByBucket(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusFailed, CreatedAt: day(1)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(200), Status: domain.StatusSuccess, CreatedAt: day(2)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(300), Status: domain.StatusSuccess, CreatedAt: day(2)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(900), Status: domain.StatusSuccess, CreatedAt: day(9)})
+
+	boundaries := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	volumes, err := repo.StatusVolumeByBucket(ctx, boundaries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.BucketStatusVolume{
+		{Bucket: 0, StatusVolume: domain.StatusVolume{Status: domain.StatusFailed, Count: 1, Sum: idr(100), Net: idr(100), Largest: idr(100)}},
+		{Bucket: 1, StatusVolume: domain.StatusVolume{Status: domain.StatusSuccess, Count: 2, Sum: idr(500), Net: idr(500), Largest: idr(300)}},
+	}
+	if len(volumes) != len(want) || volumes[0] != want[0] || volumes[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, volumes)
+	}
+}
+
+func TestTransactionRepository_AmountDistribution(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, amount := range []int64{500, 100, 300, 200, 400} {
+		_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(amount), Status: domain.StatusSuccess, CreatedAt: at})
+	}
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(9999), Status: domain.StatusFailed, CreatedAt: at})
+	_ = repo.Create(ctx, &domain.Transaction{
+		UserID: 1, Amount: domain.Money{Minor: 7777, Currency: domain.CurrencyUSD}, Status: domain.StatusSuccess, CreatedAt: at,
+	})
+
+	filter := domain.AmountFilter{
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Currency: domain.CurrencyIDR,
+	}
+
+	summary, err := repo.AmountSummary(ctx, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Count != 5 || summary.Min != idr(100) || summary.Max != idr(500) {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	// rank boleh berulang dan tidak urut, rank di luar data bernilai nol
+	amounts, err := repo.AmountsAtRanks(ctx, filter, []int64{3, 5, 1, 5, 9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(amounts, []domain.Money{idr(300), idr(500), idr(100), idr(500), idr(0)}) {
+		t.Fatalf("unexpected amounts at ranks: %v", amounts)
+	}
+
+	histogram, err := repo.AmountHistogram(ctx, filter, []int64{0, 250, 500, 750})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []domain.HistogramCount{{Bucket: 0, Count: 2}, {Bucket: 1, Count: 2}, {Bucket: 2, Count: 1}}
+	if len(histogram) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, histogram)
+	}
+	for i := range want {
+		if histogram[i] != want[i] {
+			t.Fatalf("expected %+v, got %+v", want, histogram)
+		}
+	}
+
+	failed := domain.StatusFailed
+	filter.Status = &failed
+	summary, _ = repo.AmountSummary(ctx, filter)
+	if summary.Count != 1 || summary.Max != idr(9999) {
+		t.Fatalf("expected only failed transaction, got %+v", summary)
+	}
+}
+
+func TestTransactionRepository_TopUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	at := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	create := func(userID uint, amount int64, status domain.TransactionStatus) {
+		_ = repo.Create(ctx, &domain.Transaction{UserID: userID, Amount: idr(amount), Status: status, CreatedAt: at})
+	}
+	create(1, 5000, domain.StatusSuccess)
+	create(2, 1000, domain.StatusSuccess)
+	create(2, 1000, domain.StatusSuccess)
+	create(2, 1000, domain.StatusSuccess)
+	create(3, 3000, domain.StatusSuccess)
+	create(4, 99999, domain.StatusFailed)
+
+	filter := domain.TopUsersFilter{
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		Currency: domain.CurrencyIDR,
+		By:       domain.RankByVolume,
+		Limit:    2,
+	}
+
+	users, err := repo.TopUsers(ctx, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// user 2 dan 3 seri 3000, user_id terkecil menang
+	want := []domain.UserVolume{
+		{UserID: 1, Count: 1, Volume: idr(5000)},
+		{UserID: 2, Count: 3, Volume: idr(3000)},
+	}
+	if len(users) != len(want) || users[0] != want[0] || users[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, users)
+	}
+
+	filter.By = domain.RankByCount
+	filter.Limit = 1
+	users, _ = repo.TopUsers(ctx, filter)
+	if len(users) != 1 || users[0].UserID != 2 {
+		t.Fatalf("expected user 2 to lead by count, got %+v", users)
+	}
+}
+
+func TestTransactionRepository_FindAll_Cursor(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	// tiga transaksi dengan created_at sama, urutan ditentukan id
+	same := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusPending, CreatedAt: same})
+	}
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusPending, CreatedAt: same.Add(time.Hour)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusPending, CreatedAt: same.Add(-time.Hour)})
+
+	ids := func(txs []domain.Transaction) []uint {
+		result := make([]uint, 0, len(txs))
+		for _, tx := range txs {
+			result = append(result, tx.ID)
+		}
+		return result
+	}
+
+	first, err := repo.FindAll(ctx, domain.TransactionFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ids(first); len(got) != 2 || got[0] != 4 || got[1] != 3 {
+		t.Fatalf("expected [4 3], got %v", got)
+	}
+
+	next, _ := repo.FindAll(ctx, domain.TransactionFilter{Limit: 2, Cursor: domain.NewCursor(first[1], domain.DefaultSort, false)})
+	if got := ids(next); len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Fatalf("expected [2 1] after cursor, got %v", got)
+	}
+
+	prev, _ := repo.FindAll(ctx, domain.TransactionFilter{Limit: 2, Cursor: domain.NewCursor(next[0], domain.DefaultSort, true)})
+	if got := ids(prev); len(got) != 2 || got[0] != 4 || got[1] != 3 {
+		t.Fatalf("expected [4 3] before cursor, got %v", got)
+	}
+
+	last, _ := repo.FindAll(ctx, domain.TransactionFilter{Limit: 2, Cursor: domain.NewCursor(next[1], domain.DefaultSort, false)})
+	if got := ids(last); len(got) != 1 || got[0] != 5 {
+		t.Fatalf("expected [5] on last page, got %v", got)
+	}
+}
+
+func TestTransactionRepository_Count(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusPending})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess})
+	_ = repo.Delete(ctx, 4)
+
+	users := []uint{1}
+	statuses := []domain.TransactionStatus{domain.StatusSuccess}
+
+	tests := []struct {
+		name   string
+		filter domain.TransactionFilter
+		want   int64
+	}{
+		{name: "All", filter: domain.TransactionFilter{}, want: 3},
+		{name: "Ignores Limit And Offset", filter: domain.TransactionFilter{Limit: 1, Offset: 1}, want: 3},
+		{name: "User", filter: domain.TransactionFilter{UserIDs: users}, want: 2},
+		{name: "User And Status", filter: domain.TransactionFilter{UserIDs: users, Statuses: statuses}, want: 1},
+		{name: "Include Deleted", filter: domain.TransactionFilter{IncludeDeleted: true}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Count(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransactionRepository_FindAll_Ranges(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: idr(100), Status: domain.StatusSuccess, CreatedAt: day(1)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 2, Amount: idr(500), Status: domain.StatusFailed, CreatedAt: day(2)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 3, Amount: idr(900), Status: domain.StatusPending, CreatedAt: day(3)})
+	_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: domain.Money{Minor: 500, Currency: domain.CurrencyUSD}, Status: domain.StatusSuccess, CreatedAt: day(2)})
+
+	from, to := day(2), day(3)
+	minAmount, maxAmount := idr(200), idr(900)
+
+	tests := []struct {
+		name   string
+		filter domain.TransactionFilter
+		want   []uint
+	}{
+		{name: "To Is Inclusive", filter: domain.TransactionFilter{From: &from, To: &to}, want: []uint{3, 4, 2}},
+		{name: "Exclusive To", filter: domain.TransactionFilter{From: &from, To: &to, ToExclusive: true}, want: []uint{4, 2}},
+		{name: "Amount Range Matches Currency", filter: domain.TransactionFilter{MinAmount: &minAmount, MaxAmount: &maxAmount}, want: []uint{3, 2}},
+		{name: "Multiple Users", filter: domain.TransactionFilter{UserIDs: []uint{2, 3}}, want: []uint{3, 2}},
+		{
+			name:   "Multiple Statuses",
+			filter: domain.TransactionFilter{Statuses: []domain.TransactionStatus{domain.StatusFailed, domain.StatusPending}},
+			want:   []uint{3, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.FindAll(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make([]uint, 0, len(result))
+			for _, tx := range result {
+				got = append(got, tx.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransactionRepository_FindAll_Sort(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	// amount 300 muncul dua kali supaya tiebreaker id ikut diuji
+	for i, amount := range []int64{300, 100, 300, 200, 500} {
+		_ = repo.Create(ctx, &domain.Transaction{
+			UserID:    uint(i%2 + 1),
+			Amount:    idr(amount),
+			Status:    domain.StatusSuccess,
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	ids := func(txs []domain.Transaction) []uint {
+		result := make([]uint, 0, len(txs))
+		for _, tx := range txs {
+			result = append(result, tx.ID)
+		}
+		return result
+	}
+
+	sort, _ := domain.ParseSort("user_id,-amount")
+	all, err := repo.FindAll(ctx, domain.TransactionFilter{Sort: sort})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// user 1: id 1 (300), 3 (300), 5 (500); user 2: id 2 (100), 4 (200)
+	if got := ids(all); !slices.Equal(got, []uint{5, 3, 1, 4, 2}) {
+		t.Fatalf("expected [5 3 1 4 2], got %v", got)
+	}
+
+	// keyset per dua baris harus menghasilkan urutan yang sama tanpa loncat atau duplikat
+	var paged []uint
+	filter := domain.TransactionFilter{Sort: sort, Limit: 2}
+	for {
+		page, err := repo.FindAll(ctx, filter)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, ids(page)...)
+		filter.Cursor = domain.NewCursor(page[len(page)-1], sort, false)
+	}
+	if !slices.Equal(paged, ids(all)) {
+		t.Fatalf("expected %v when paging, got %v", ids(all), paged)
+	}
+
+	prev, _ := repo.FindAll(ctx, domain.TransactionFilter{Sort: sort, Limit: 2, Cursor: domain.NewCursor(all[3], sort, true)})
+	if got := ids(prev); !slices.Equal(got, []uint{3, 1}) {
+		t.Fatalf("expected [3 1] before id 4, got %v", got)
+	}
+
+	if _, err := repo.FindAll(ctx, domain.TransactionFilter{Sort: domain.Sort{{Field: "amount_minor"}}}); !errors.Is(err, domain.ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort, got %v", err)
+	}
+}
+
+func TestTransactionRepository_FindAll_SortAmountByCurrency(t *testing.T) {
+	ctx := context.Background()
+	repo := setupTestRepo(t)
+
+	// minor unit: 1 USD (100) lebih kecil dari 2 IDR (200) jika dibandingkan langsung
+	amounts := []domain.Money{
+		{Minor: 100, Currency: domain.CurrencyUSD},
+		idr(200),
+		{Minor: 50, Currency: domain.CurrencyUSD},
+		idr(300),
+	}
+	for _, amount := range amounts {
+		_ = repo.Create(ctx, &domain.Transaction{UserID: 1, Amount: amount, Status: domain.StatusSuccess})
+	}
+
+	sort, _ := domain.ParseSort("-amount")
+	all, err := repo.FindAll(ctx, domain.TransactionFilter{Sort: sort})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, tx := range all {
+		got = append(got, tx.Amount.String()+" "+string(tx.Amount.Currency))
+	}
+	want := []string{"3.00 IDR", "2.00 IDR", "1.00 USD", "0.50 USD"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// cursor menyimpan currency sehingga keyset tidak melompati mata uang lain
+	page, err := repo.FindAll(ctx, domain.TransactionFilter{Sort: sort, Limit: 2, Cursor: domain.NewCursor(all[1], sort, false)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != all[2].ID || page[1].ID != all[3].ID {
+		t.Fatalf("expected USD rows after last IDR row, got %+v", page)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type WalletModel struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_wallets_user_currency;not null"`
+	Currency     string `gorm:"uniqueIndex:idx_wallets_user_currency;size:3;not null"`
+	BalanceMinor int64  `gorm:"not null;default:0"`
+	UpdatedAt    time.Time
+}
+
+// Mapper
+func walletToDomain(m *WalletModel) domain.Wallet {
+	return domain.Wallet{
+		ID:     m.ID,
+		UserID: m.UserID,
+		Balance: domain.Money{
+			Minor:    m.BalanceMinor,
+			Currency: domain.Currency(m.Currency),
+		},
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func walletFromDomain(d *domain.Wallet) WalletModel {
+	return WalletModel{
+		ID:           d.ID,
+		UserID:       d.UserID,
+		Currency:     string(d.Balance.Currency),
+		BalanceMinor: d.Balance.Minor,
+		UpdatedAt:    d.UpdatedAt,
+	}
+}
+
+type WalletRepository struct {
+	db *gorm.DB
+}
+
+// Constructor
+func NewWalletRepository(db *gorm.DB) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+// FindForUpdate harus dipanggil di dalam Transactor.WithinTransaction.
+// MySQL mengunci baris lewat SELECT ... FOR UPDATE. SQLite mengabaikan
+// FOR UPDATE, tetapi INSERT di awal sudah mengambil write lock database
+// sehingga transaction lain yang menyentuh wallet menunggu sampai commit.
+func (r *WalletRepository) FindForUpdate(ctx context.Context, userID uint, currency domain.Currency) (*domain.Wallet, error) {
+	db := dbFromContext(ctx, r.db)
+
+	// wallet kosong dibuat saat pertama dipakai, diabaikan jika sudah ada
+	model := walletFromDomain(domain.NewWallet(userID, currency))
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model).Error; err != nil {
+		return nil, err
+	}
+
+	var locked WalletModel
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND currency = ?", userID, string(currency)).
+		First(&locked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := walletToDomain(&locked)
+	return &wallet, nil
+}
+
+func (r *WalletRepository) FindByUserID(ctx context.Context, userID uint) ([]domain.Wallet, error) {
+	var models []WalletModel
+
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("currency").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]domain.Wallet, 0, len(models))
+	for i := range models {
+		wallets = append(wallets, walletToDomain(&models[i]))
+	}
+
+	return wallets, nil
+}
+
+func (r *WalletRepository) Update(ctx context.Context, wallet *domain.Wallet) error {
+	return dbFromContext(ctx, r.db).Model(&WalletModel{}).
+		Where("id = ?", wallet.ID).
+		Updates(map[string]interface{}{
+			"balance_minor": wallet.Balance.Minor,
+			"updated_at":    wallet.UpdatedAt,
+		}).Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
+
+func TestWalletRepository_FindForUpdateCreatesWallet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewWalletRepository(setupTestDB(t))
+
+	first, err := repo.FindForUpdate(ctx, 1, domain.CurrencyIDR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.ID == 0 || first.Balance != idr(0) {
+		t.Fatalf("expected empty wallet, got %+v", first)
+	}
+
+	if err := first.Credit(idr(500)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := repo.FindForUpdate(ctx, 1, domain.CurrencyIDR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.ID != first.ID || second.Balance != idr(500) {
+		t.Fatalf("expected same wallet with balance 500, got %+v", second)
+	}
+
+	wallets, err := repo.FindByUserID(ctx, 1)
+	if err != nil || len(wallets) != 1 {
+		t.Fatalf("expected 1 wallet, got %d (%v)", len(wallets), err)
+	}
+}
+
+// Memakai database file dengan banyak koneksi; setupTestDB hanya punya satu
+// koneksi sehingga goroutine tidak pernah benar-benar bersamaan.
+func TestWalletRepository_ConcurrentDebitCannotOverdraw(t *testing.T) {
+	ctx := context.Background()
+	db := setupFileTestDB(t)
+	transactor := NewTransactor(db)
+	repo := NewWalletRepository(db)
+
+	seed := WalletModel{UserID: 1, Currency: string(domain.CurrencyIDR), BalanceMinor: 1000}
+	if err := db.Create(&seed).Error; err != nil {
+		t.Fatalf("failed seed wallet: %v", err)
+	}
+
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		succeeded    int
+		insufficient int
+	)
+	start := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				w, err := repo.FindForUpdate(ctx, 1, domain.CurrencyIDR)
+				if err != nil {
+					return err
+				}
+				// Perlebar jendela race: tanpa lock semua goroutine membaca
+				// saldo yang sama sebelum ada yang menulis
+				time.Sleep(20 * time.Millisecond)
+				if err := w.Debit(idr(300)); err != nil {
+					return err
+				}
+				return repo.Update(ctx, w)
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, domain.ErrInsufficientFunds):
+				insufficient++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != 3 || insufficient != 2 {
+		t.Fatalf("expected 3 debits and 2 rejections, got %d and %d", succeeded, insufficient)
+	}
+
+	final, _ := repo.FindForUpdate(ctx, 1, domain.CurrencyIDR)
+	if final.Balance != idr(100) {
+		t.Fatalf("expected balance 100, got %+v", final.Balance)
+	}
+}
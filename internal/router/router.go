@@ -9,7 +9,12 @@ import (
 func RegisterRoutes(
 	r *gin.Engine,
 	txHandler *handler.TransactionHandler,
+	refundHandler *handler.RefundHandler,
+	authHandler *handler.AuthorizationHandler,
+	ledgerHandler *handler.LedgerHandler,
+	walletHandler *handler.WalletHandler,
 	dashboardHandler *handler.DashboardHandler,
+	streamHandler *handler.StreamHandler,
 ) {
 	api := r.Group("/api")
 
@@ -21,11 +26,38 @@ func RegisterRoutes(
 		transactions.GET("/:id", txHandler.GetByID)
 		transactions.PUT("/:id", txHandler.UpdateStatus)
 		transactions.DELETE("/:id", txHandler.Delete)
+		transactions.GET("/:id/history", txHandler.History)
+		transactions.POST("/:id/restore", txHandler.Restore)
+
+		transactions.POST("/:id/refunds", refundHandler.Create)
+		transactions.GET("/:id/refunds", refundHandler.GetByTransactionID)
+
+		transactions.POST("/:id/authorize", authHandler.Authorize)
+		transactions.POST("/:id/capture", authHandler.Capture)
+		transactions.POST("/:id/void", authHandler.Void)
+	}
+
+	// Ledger routes
+	ledger := api.Group("/ledger")
+	{
+		ledger.GET("/accounts/:id/balance", ledgerHandler.AccountBalance)
+		ledger.GET("/check", ledgerHandler.Check)
+	}
+
+	// User wallet routes
+	users := api.Group("/users")
+	{
+		users.GET("/:id/wallets", walletHandler.GetByUserID)
+		users.GET("/:id/stats", dashboardHandler.UserStats)
 	}
 
 	// Dashboard routes
 	dashboard := api.Group("/dashboard")
 	{
 		dashboard.GET("/summary", dashboardHandler.Summary)
+		dashboard.GET("/timeseries", dashboardHandler.Timeseries)
+		dashboard.GET("/distribution", dashboardHandler.Distribution)
+		dashboard.GET("/top-users", dashboardHandler.TopUsers)
+		dashboard.GET("/stream", streamHandler.Stream)
 	}
 }
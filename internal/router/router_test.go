@@ -14,6 +14,11 @@ func TestRegisterRoutes(t *testing.T) {
 	RegisterRoutes(
 		r,
 		&handler.TransactionHandler{},
+		&handler.RefundHandler{},
+		&handler.AuthorizationHandler{},
+		&handler.LedgerHandler{},
+		&handler.WalletHandler{},
 		&handler.DashboardHandler{},
+		&handler.StreamHandler{},
 	)
 }
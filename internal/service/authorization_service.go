@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// expiredAuthorizationBatch adalah jumlah authorization expired yang
+// di-void per putaran worker
+const expiredAuthorizationBatch = 100
+
+// errSkipVoid membatalkan database transaction tanpa dianggap error
+var errSkipVoid = errors.New("authorization no longer expired")
+
+type AuthorizationService struct {
+	transactor domain.Transactor
+	repo       domain.TransactionRepository
+	ledger     *LedgerService
+	wallets    *WalletService
+	feed       *Broadcaster
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewAuthorizationService menerima feed untuk live feed dashboard; setiap
+// perubahan status (authorize, capture, void) di-publish setelah commit
+func NewAuthorizationService(
+	transactor domain.Transactor,
+	repo domain.TransactionRepository,
+	ledger *LedgerService,
+	wallets *WalletService,
+	feed *Broadcaster,
+	ttl time.Duration,
+) *AuthorizationService {
+	return &AuthorizationService{
+		transactor: transactor,
+		repo:       repo,
+		ledger:     ledger,
+		wallets:    wallets,
+		feed:       feed,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// Authorize menahan amount transaksi pending dari wallet user sampai
+// authorization di-capture, di-void atau expired.
+func (s *AuthorizationService) Authorize(ctx context.Context, id uint) (*domain.Transaction, error) {
+	return s.withLockedTransaction(ctx, id, func(ctx context.Context, tx *domain.Transaction) error {
+		if err := tx.Authorize(s.now().Add(s.ttl)); err != nil {
+			return err
+		}
+
+		return s.wallets.Debit(ctx, tx.UserID, tx.Authorized)
+	})
+}
+
+// Capture menagih amount dalam satuan mayor; amount kosong berarti seluruh
+// amount yang di-authorize. Sisa yang tidak di-capture dikembalikan ke wallet.
+func (s *AuthorizationService) Capture(
+	ctx context.Context,
+	id uint,
+	amount string,
+	currency domain.Currency,
+) (*domain.Transaction, error) {
+	return s.withLockedTransaction(ctx, id, func(ctx context.Context, tx *domain.Transaction) error {
+		if currency != "" && currency != tx.Authorized.Currency {
+			return domain.ErrCurrencyMismatch
+		}
+
+		captureAmount := tx.Authorized
+		if amount != "" {
+			var err error
+			captureAmount, err = domain.ParseMoney(amount, tx.Authorized.Currency)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Capture(captureAmount, s.now()); err != nil {
+			return err
+		}
+
+		if err := s.release(ctx, tx); err != nil {
+			return err
+		}
+
+		return s.ledger.RecordPayment(ctx, tx)
+	})
+}
+
+// Void membatalkan authorization dan mengembalikan seluruh dana ke wallet
+func (s *AuthorizationService) Void(ctx context.Context, id uint) (*domain.Transaction, error) {
+	return s.withLockedTransaction(ctx, id, func(ctx context.Context, tx *domain.Transaction) error {
+		if err := tx.Void(); err != nil {
+			return err
+		}
+
+		return s.release(ctx, tx)
+	})
+}
+
+// VoidExpired me-void authorization yang sudah expired. Setiap transaksi
+// di-void dalam database transaction sendiri supaya satu kegagalan tidak
+// membatalkan yang lain. Mengembalikan jumlah transaksi yang di-void.
+func (s *AuthorizationService) VoidExpired(ctx context.Context) (int, error) {
+	now := s.now()
+
+	expired, err := s.repo.FindExpiredAuthorizations(ctx, now, expiredAuthorizationBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		voided int
+		errs   []error
+	)
+	for _, candidate := range expired {
+		_, err := s.withLockedTransaction(ctx, candidate.ID, func(ctx context.Context, tx *domain.Transaction) error {
+			// Bisa saja sudah di-capture atau di-void sejak query di atas
+			if tx.Status != domain.StatusAuthorized || !tx.IsAuthorizationExpired(now) {
+				return errSkipVoid
+			}
+
+			if err := tx.Void(); err != nil {
+				return err
+			}
+
+			return s.release(ctx, tx)
+		})
+
+		switch {
+		case err == nil:
+			voided++
+		case errors.Is(err, errSkipVoid):
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	return voided, errors.Join(errs...)
+}
+
+// release mengembalikan bagian authorization yang tidak di-capture ke wallet
+func (s *AuthorizationService) release(ctx context.Context, tx *domain.Transaction) error {
+	released := tx.ReleasedAmount()
+	if !released.IsPositive() {
+		return nil
+	}
+
+	return s.wallets.Credit(ctx, tx.UserID, released)
+}
+
+func (s *AuthorizationService) withLockedTransaction(
+	ctx context.Context,
+	id uint,
+	fn func(ctx context.Context, tx *domain.Transaction) error,
+) (*domain.Transaction, error) {
+	var (
+		tx        *domain.Transaction
+		oldStatus domain.TransactionStatus
+	)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		tx, err = s.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		oldStatus = tx.Status
+
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+
+		return s.repo.Update(ctx, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishUpdated(ctx, s.feed, *tx, oldStatus)
+
+	return tx, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func newTestAuthorizationService(repo *MockRepo, wallets *fakeWalletRepo, ledger *fakeLedgerRepo, now time.Time) *AuthorizationService {
+	svc := NewAuthorizationService(
+		fakeTransactor{},
+		repo,
+		NewLedgerService(ledger),
+		NewWalletService(wallets),
+		NewBroadcaster(1, zap.NewNop()),
+		time.Hour,
+	)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestAuthorizationService_AuthorizeAndCapture(t *testing.T) {
+	ctx := context.Background()
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := new(MockRepo)
+	wallets := &fakeWalletRepo{wallets: []domain.Wallet{{ID: 1, UserID: 7, Balance: idr(10000)}}}
+	ledger := &fakeLedgerRepo{}
+	svc := newTestAuthorizationService(repo, wallets, ledger, now)
+
+	tx := domain.NewTransaction(7, idr(8000))
+	tx.ID = 1
+	repo.On("FindByIDForUpdate", uint(1)).Return(tx, nil)
+	repo.On("Update", mock.Anything).Return(nil)
+
+	authorized, err := svc.Authorize(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusAuthorized, authorized.Status)
+	assert.Equal(t, now.Add(time.Hour), *authorized.AuthorizationExpiresAt)
+	assert.Equal(t, idr(2000), wallets.wallets[0].Balance)
+	assert.Empty(t, ledger.entries)
+
+	captured, err := svc.Capture(ctx, 1, "50", "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusCaptured, captured.Status)
+	assert.Equal(t, idr(5000), captured.Amount)
+
+	// Sisa 3000 yang tidak di-capture kembali ke wallet
+	assert.Equal(t, idr(5000), wallets.wallets[0].Balance)
+	assert.Len(t, ledger.entries, 1)
+}
+
+func TestAuthorizationService_Authorize_InsufficientFunds(t *testing.T) {
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+
+	repo := new(MockRepo)
+	svc := newTestAuthorizationService(repo, &fakeWalletRepo{}, &fakeLedgerRepo{}, time.Now())
+
+	tx := domain.NewTransaction(7, idr(8000))
+	tx.ID = 1
+	repo.On("FindByIDForUpdate", uint(1)).Return(tx, nil)
+
+	_, err := svc.Authorize(context.Background(), 1)
+	assert.ErrorIs(t, err, domain.ErrInsufficientFunds)
+	repo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAuthorizationService_VoidExpired(t *testing.T) {
+	ctx := context.Background()
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiredAt := now.Add(-time.Minute)
+
+	expired := &domain.Transaction{
+		ID: 1, UserID: 7, Amount: idr(3000), Authorized: idr(3000),
+		AuthorizationExpiresAt: &expiredAt, Status: domain.StatusAuthorized,
+	}
+	// Sudah di-capture setelah query expired dijalankan
+	captured := &domain.Transaction{
+		ID: 2, UserID: 7, Amount: idr(1000), Authorized: idr(1000),
+		AuthorizationExpiresAt: &expiredAt, Status: domain.StatusCaptured,
+	}
+
+	repo := new(MockRepo)
+	wallets := &fakeWalletRepo{wallets: []domain.Wallet{{ID: 1, UserID: 7, Balance: idr(0)}}}
+	svc := newTestAuthorizationService(repo, wallets, &fakeLedgerRepo{}, now)
+
+	repo.On("FindExpiredAuthorizations", now, expiredAuthorizationBatch).
+		Return([]domain.Transaction{*expired, *captured}, nil).Once()
+	repo.On("FindByIDForUpdate", uint(1)).Return(expired, nil).Once()
+	repo.On("FindByIDForUpdate", uint(2)).Return(captured, nil).Once()
+	repo.On("Update", mock.MatchedBy(func(tx *domain.Transaction) bool {
+		return tx.ID == 1 && tx.Status == domain.StatusVoided
+	})).Return(nil).Once()
+
+	events, unsubscribe := svc.feed.Subscribe(FeedFilter{})
+	defer unsubscribe()
+
+	voided, err := svc.VoidExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, voided)
+	assert.Equal(t, idr(3000), wallets.wallets[0].Balance)
+	repo.AssertExpectations(t)
+
+	// hanya transaksi yang benar-benar di-void yang masuk live feed
+	event := <-events
+	assert.Equal(t, uint(1), event.Transaction.ID)
+	assert.Equal(t, domain.StatusAuthorized, event.OldStatus)
+	assert.Equal(t, domain.StatusVoided, event.Transaction.Status)
+	assert.Empty(t, events)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
+)
+
+// FeedEvent adalah transaksi yang baru dibuat atau berubah status,
+// dikirim ke client live feed setelah database transaction di-commit
+type FeedEvent struct {
+	Type        domain.TransactionEventType `json:"type"`
+	Transaction domain.Transaction          `json:"transaction"`
+	// OldStatus kosong untuk event created
+	OldStatus domain.TransactionStatus `json:"old_status,omitempty"`
+	At        time.Time                `json:"at"`
+}
+
+// FeedFilter memilih event untuk satu client, field nil berarti semua.
+// Status dicocokkan dengan status transaksi setelah event.
+type FeedFilter struct {
+	UserID *uint
+	Status *domain.TransactionStatus
+}
+
+// Matches mengecek apakah event lolos filter
+func (f FeedFilter) Matches(e FeedEvent) bool {
+	if f.UserID != nil && e.Transaction.UserID != *f.UserID {
+		return false
+	}
+	if f.Status != nil && e.Transaction.Status != *f.Status {
+		return false
+	}
+	return true
+}
+
+type subscriber struct {
+	filter FeedFilter
+	events chan FeedEvent
+}
+
+// Broadcaster meneruskan FeedEvent ke semua subscriber di proses yang sama.
+// Publish tidak pernah blocking: event untuk subscriber yang buffer-nya
+// penuh dibuang supaya client lambat tidak menahan request lain.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	buffer      int
+	logger      *zap.Logger
+}
+
+// NewBroadcaster menerima ukuran buffer event per subscriber. logger dipakai
+// jika context publish tidak membawa logger request, misal worker expiry.
+func NewBroadcaster(buffer int, logger *zap.Logger) *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[*subscriber]struct{}),
+		buffer:      buffer,
+		logger:      logger,
+	}
+}
+
+// Subscribe mendaftarkan client baru. unsubscribe wajib dipanggil saat
+// client selesai; setelah itu channel events ditutup.
+func (b *Broadcaster) Subscribe(filter FeedFilter) (events <-chan FeedEvent, unsubscribe func()) {
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan FeedEvent, b.buffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			close(sub.events)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish mengirim event ke subscriber yang filter-nya cocok dan
+// mengembalikan jumlah subscriber yang event-nya dibuang karena buffer penuh
+func (b *Broadcaster) Publish(event FeedEvent) (dropped int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// publishUpdated meneruskan perubahan status transaksi ke live feed.
+// Dipanggil setelah commit; event yang dibuang untuk client lambat dicatat
+// supaya dashboard yang tertinggal bisa dilacak.
+func publishUpdated(ctx context.Context, feed *Broadcaster, tx domain.Transaction, oldStatus domain.TransactionStatus) {
+	publishFeed(ctx, feed, FeedEvent{
+		Type:        domain.EventTransactionUpdated,
+		Transaction: tx,
+		OldStatus:   oldStatus,
+		At:          time.Now(),
+	})
+}
+
+func publishFeed(ctx context.Context, feed *Broadcaster, event FeedEvent) {
+	if dropped := feed.Publish(event); dropped > 0 {
+		logging.FromContext(ctx, feed.logger).Warn("feed event dropped for slow subscribers",
+			zap.String("event_type", string(event.Type)),
+			zap.Uint("transaction_id", event.Transaction.ID),
+			zap.Int("dropped", dropped),
+		)
+	}
+}
+
+// Subscribers adalah jumlah client yang sedang terhubung
+func (b *Broadcaster) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
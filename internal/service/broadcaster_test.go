@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster(1, zap.NewNop())
+
+	userID := uint(7)
+	success := domain.StatusSuccess
+
+	all, unsubscribeAll := b.Subscribe(FeedFilter{})
+	byUser, unsubscribeUser := b.Subscribe(FeedFilter{UserID: &userID, Status: &success})
+	assert.Equal(t, 2, b.Subscribers())
+
+	other := FeedEvent{Type: domain.EventTransactionCreated, Transaction: domain.Transaction{ID: 1, UserID: 8, Status: domain.StatusPending}}
+	b.Publish(other)
+
+	assert.Equal(t, other, <-all)
+	assert.Empty(t, byUser, "event for another user must be filtered out")
+
+	t.Run("Slow Subscriber Does Not Block", func(t *testing.T) {
+		match := FeedEvent{Type: domain.EventTransactionUpdated, Transaction: domain.Transaction{ID: 2, UserID: 7, Status: domain.StatusSuccess}}
+
+		// buffer 1: event kedua dibuang, Publish tetap kembali
+		assert.Zero(t, b.Publish(match))
+		assert.Equal(t, 2, b.Publish(match), "both full subscribers must be reported as dropped")
+
+		assert.Len(t, byUser, 1)
+		assert.Equal(t, match, <-byUser)
+		<-all
+	})
+
+	t.Run("Unsubscribe Closes Channel", func(t *testing.T) {
+		unsubscribeUser()
+		unsubscribeUser()
+
+		_, open := <-byUser
+		assert.False(t, open)
+		assert.Equal(t, 1, b.Subscribers())
+
+		unsubscribeAll()
+		b.Publish(other)
+		assert.Equal(t, 0, b.Subscribers())
+	})
+}
+
+func TestPublishFeed_LogsWithoutRequestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	// buffer 0: subscriber tanpa reader selalu kehilangan event
+	feed := NewBroadcaster(0, zap.New(core))
+	_, unsubscribe := feed.Subscribe(FeedFilter{})
+	defer unsubscribe()
+
+	// context worker tidak membawa logger request
+	publishUpdated(context.Background(), feed, domain.Transaction{ID: 3, Status: domain.StatusVoided}, domain.StatusAuthorized)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "feed event dropped for slow subscribers", entries[0].Message)
+		assert.Equal(t, uint64(3), entries[0].ContextMap()["transaction_id"])
+	}
+}
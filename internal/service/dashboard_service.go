@@ -1,43 +1,658 @@
 package service
 
-import "transaction-technical-test/internal/domain"
+import (
+	"context"
+	"sort"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
 
 type DashboardSummary struct {
-	TotalSuccessToday    float64              `json:"total_success_today"`
-	AverageAmountPerUser float64              `json:"average_amount_per_user"`
-	LatestTransactions   []domain.Transaction `json:"latest_transactions"`
+	// Timezone adalah zona waktu yang dipakai untuk batas "hari ini"
+	Timezone          string         `json:"timezone"`
+	TotalSuccessToday []domain.Money `json:"total_success_today"`
+	// AverageAmountPerUser berisi nilai yang sama dengan
+	// AverageTransactionAmount, dipertahankan untuk client lama.
+	//
+	// Deprecated: pakai AverageTransactionAmount atau AverageTotalPerUser.
+	AverageAmountPerUser     []domain.Money       `json:"average_amount_per_user"`
+	AverageTransactionAmount []domain.Money       `json:"average_transaction_amount"`
+	AverageTotalPerUser      []domain.Money       `json:"average_total_per_user"`
+	LatestTransactions       []domain.Transaction `json:"latest_transactions"`
+	StatusBreakdown          *StatusBreakdown     `json:"status_breakdown"`
+}
+
+// SummaryWindow adalah rentang waktu status breakdown di summary
+type SummaryWindow string
+
+const (
+	WindowToday     SummaryWindow = "today"
+	WindowLast7Days SummaryWindow = "7d"
+	WindowCustom    SummaryWindow = "custom"
+)
+
+// ParseSummaryWindow memvalidasi window dari query string
+func ParseSummaryWindow(s string) (SummaryWindow, error) {
+	switch w := SummaryWindow(s); w {
+	case WindowToday, WindowLast7Days, WindowCustom:
+		return w, nil
+	default:
+		return "", domain.ErrInvalidWindow
+	}
+}
+
+// SummaryQuery adalah parameter GetSummary. Location nil berarti zona waktu
+// bisnis default, Window kosong berarti today. From dan To hanya dipakai
+// untuk WindowCustom.
+type SummaryQuery struct {
+	Location *time.Location
+	Window   SummaryWindow
+	From     time.Time
+	To       time.Time
+}
+
+// PeriodBreakdown adalah agregat per status dalam satu periode [From, To)
+type PeriodBreakdown struct {
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Count       int64         `json:"count"`
+	ByStatus    []StatusTotal `json:"by_status"`
+	SuccessRate float64       `json:"success_rate"`
+}
+
+// StatusBreakdown membandingkan window yang dipilih dengan window
+// sebelumnya yang sama panjang. Delta adalah current dikurangi previous.
+type StatusBreakdown struct {
+	Window   SummaryWindow   `json:"window"`
+	Current  PeriodBreakdown `json:"current"`
+	Previous PeriodBreakdown `json:"previous"`
+	Delta    BreakdownDelta  `json:"delta"`
+}
+
+type BreakdownDelta struct {
+	Count       int64         `json:"count"`
+	ByStatus    []StatusTotal `json:"by_status"`
+	SuccessRate float64       `json:"success_rate"`
 }
 
 type DashboardService struct {
-	repo domain.TransactionRepository
+	repo     domain.TransactionRepository
+	location *time.Location
+	now      func() time.Time
 }
 
-func NewDashboardService(repo domain.TransactionRepository) *DashboardService {
+// NewDashboardService menerima zona waktu bisnis default untuk batas hari
+func NewDashboardService(repo domain.TransactionRepository, location *time.Location) *DashboardService {
 	return &DashboardService{
-		repo: repo,
+		repo:     repo,
+		location: location,
+		now:      time.Now,
 	}
 }
 
+// Location adalah zona waktu bisnis default
+func (s *DashboardService) Location() *time.Location {
+	return s.location
+}
+
 // Get Summary untuk dashboard
-func (s *DashboardService) GetSummary() (*DashboardSummary, error) {
-	totalToday, err := s.repo.TotalSuccessToday()
+func (s *DashboardService) GetSummary(ctx context.Context, q SummaryQuery) (*DashboardSummary, error) {
+	loc := q.Location
+	if loc == nil {
+		loc = s.location
+	}
+
+	boundaries, err := s.summaryWindow(q, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	totalToday, err := s.repo.TotalSuccessToday(ctx, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	avgPerUser, err := s.repo.AverageAmountPerUser()
+	avgPerTransaction, err := s.repo.AverageTransactionAmount(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	latest, err := s.repo.Latest(10)
+	avgPerUser, err := s.repo.AverageTotalPerUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repo.Latest(ctx, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.StatusVolumeByBucket(ctx, boundaries)
 	if err != nil {
 		return nil, err
 	}
 
 	return &DashboardSummary{
-		TotalSuccessToday:    totalToday,
-		AverageAmountPerUser: avgPerUser,
-		LatestTransactions:   latest,
+		Timezone:                 loc.String(),
+		TotalSuccessToday:        totalToday,
+		AverageAmountPerUser:     avgPerTransaction,
+		AverageTransactionAmount: avgPerTransaction,
+		AverageTotalPerUser:      avgPerUser,
+		LatestTransactions:       latest,
+		StatusBreakdown:          newStatusBreakdown(windowOf(q), boundaries, volumes),
+	}, nil
+}
+
+func windowOf(q SummaryQuery) SummaryWindow {
+	if q.Window == "" {
+		return WindowToday
+	}
+	return q.Window
+}
+
+// summaryWindow mengembalikan batas [previous, current, end) untuk window
+// yang dipilih; window sebelumnya selalu sama panjang dengan window sekarang.
+func (s *DashboardService) summaryWindow(q SummaryQuery, loc *time.Location) ([]time.Time, error) {
+	var start, end time.Time
+
+	switch windowOf(q) {
+	case WindowToday, WindowLast7Days:
+		y, m, d := s.now().In(loc).Date()
+		end = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		days := 1
+		if windowOf(q) == WindowLast7Days {
+			days = 7
+		}
+		start = end.AddDate(0, 0, -days)
+	case WindowCustom:
+		if !q.From.Before(q.To) {
+			return nil, domain.ErrInvalidTimeRange
+		}
+		start, end = q.From, q.To
+	default:
+		return nil, domain.ErrInvalidWindow
+	}
+
+	return []time.Time{start.Add(-end.Sub(start)), start, end}, nil
+}
+
+// newStatusBreakdown menyusun bucket 0 (previous) dan 1 (current). Setiap
+// pasangan status dan mata uang muncul di kedua periode supaya delta-nya
+// bisa dibandingkan langsung, nol jika tidak ada transaksi.
+func newStatusBreakdown(w SummaryWindow, boundaries []time.Time, volumes []domain.BucketStatusVolume) *StatusBreakdown {
+	type key struct {
+		status   domain.TransactionStatus
+		currency domain.Currency
+	}
+
+	keys := make([]key, 0)
+	periods := [2]map[key]domain.StatusVolume{{}, {}}
+	for _, v := range volumes {
+		k := key{status: v.Status, currency: v.Sum.Currency}
+		if _, seen := periods[0][k]; !seen {
+			if _, seen := periods[1][k]; !seen {
+				keys = append(keys, k)
+			}
+		}
+		periods[v.Bucket][k] = v.StatusVolume
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].status != keys[j].status {
+			return keys[i].status < keys[j].status
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	breakdowns := [2]PeriodBreakdown{}
+	for i := range breakdowns {
+		counts := make(map[domain.TransactionStatus]int64)
+		breakdowns[i] = PeriodBreakdown{
+			From:     boundaries[i],
+			To:       boundaries[i+1],
+			ByStatus: make([]StatusTotal, 0, len(keys)),
+		}
+
+		for _, k := range keys {
+			v := periods[i][k]
+			breakdowns[i].Count += v.Count
+			breakdowns[i].ByStatus = append(breakdowns[i].ByStatus, StatusTotal{
+				Status: k.status,
+				Count:  v.Count,
+				Total:  domain.Money{Minor: v.Sum.Minor, Currency: k.currency},
+			})
+			counts[k.status] += v.Count
+		}
+		breakdowns[i].SuccessRate = domain.SuccessRate(counts)
+	}
+
+	previous, current := breakdowns[0], breakdowns[1]
+	delta := BreakdownDelta{
+		Count:       current.Count - previous.Count,
+		ByStatus:    make([]StatusTotal, 0, len(keys)),
+		SuccessRate: current.SuccessRate - previous.SuccessRate,
+	}
+	for i, k := range keys {
+		delta.ByStatus = append(delta.ByStatus, StatusTotal{
+			Status: k.status,
+			Count:  current.ByStatus[i].Count - previous.ByStatus[i].Count,
+			Total: domain.Money{
+				Minor:    current.ByStatus[i].Total.Minor - previous.ByStatus[i].Total.Minor,
+				Currency: k.currency,
+			},
+		})
+	}
+
+	return &StatusBreakdown{
+		Window:   w,
+		Current:  current,
+		Previous: previous,
+		Delta:    delta,
+	}
+}
+
+// TimeseriesQuery adalah parameter GetTimeseries. Status nil berarti hanya
+// transaksi settled; Location nil berarti zona waktu bisnis default.
+type TimeseriesQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval domain.Interval
+	Status   *domain.TransactionStatus
+	Location *time.Location
+}
+
+// TimeseriesBucket berisi agregat satu bucket; Sum dan Average berisi satu
+// entry per mata uang yang muncul di rentang waktu, nol jika kosong.
+type TimeseriesBucket struct {
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Count   int64          `json:"count"`
+	Sum     []domain.Money `json:"sum"`
+	Average []domain.Money `json:"average"`
+}
+
+type Timeseries struct {
+	Timezone string             `json:"timezone"`
+	Interval domain.Interval    `json:"interval"`
+	Buckets  []TimeseriesBucket `json:"buckets"`
+}
+
+// GetTimeseries mengelompokkan transaksi per interval. Bucket tanpa
+// transaksi tetap dikembalikan dengan nilai nol supaya grafik tidak bolong.
+func (s *DashboardService) GetTimeseries(ctx context.Context, q TimeseriesQuery) (*Timeseries, error) {
+	loc := q.Location
+	if loc == nil {
+		loc = s.location
+	}
+
+	boundaries, err := q.Interval.Buckets(q.From, q.To, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.VolumeByBucket(ctx, boundaries, q.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make([]domain.Currency, 0)
+	byBucket := make(map[int]map[domain.Currency]domain.BucketVolume)
+	for _, v := range volumes {
+		if byBucket[v.Bucket] == nil {
+			byBucket[v.Bucket] = make(map[domain.Currency]domain.BucketVolume)
+		}
+		if !containsCurrency(currencies, v.Sum.Currency) {
+			currencies = append(currencies, v.Sum.Currency)
+		}
+		byBucket[v.Bucket][v.Sum.Currency] = v
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	buckets := make([]TimeseriesBucket, 0, len(boundaries)-1)
+	for i := 0; i < len(boundaries)-1; i++ {
+		bucket := TimeseriesBucket{
+			Start:   boundaries[i],
+			End:     boundaries[i+1],
+			Sum:     make([]domain.Money, 0, len(currencies)),
+			Average: make([]domain.Money, 0, len(currencies)),
+		}
+
+		for _, currency := range currencies {
+			v, ok := byBucket[i][currency]
+			if !ok {
+				v.Sum = domain.Money{Currency: currency}
+			}
+			bucket.Count += v.Count
+			bucket.Sum = append(bucket.Sum, v.Sum)
+			bucket.Average = append(bucket.Average, v.Sum.DivRound(v.Count))
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return &Timeseries{
+		Timezone: loc.String(),
+		Interval: q.Interval,
+		Buckets:  buckets,
 	}, nil
 }
+
+// TopUsersQuery adalah parameter GetTopUsers. Window memakai aturan yang
+// sama dengan summary, tetapi default 7d.
+type TopUsersQuery struct {
+	Window   SummaryQuery
+	Currency domain.Currency
+	By       domain.RankBy
+	Limit    int
+}
+
+// TopUser adalah satu baris leaderboard. Share adalah porsi (0-1) dari
+// total volume dan jumlah transaksi settled di window yang sama.
+type TopUser struct {
+	Rank        int          `json:"rank"`
+	UserID      uint         `json:"user_id"`
+	Count       int64        `json:"count"`
+	Volume      domain.Money `json:"volume"`
+	VolumeShare float64      `json:"volume_share"`
+	CountShare  float64      `json:"count_share"`
+}
+
+type TopUsers struct {
+	Window      SummaryWindow `json:"window"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	By          domain.RankBy `json:"by"`
+	TotalCount  int64         `json:"total_count"`
+	TotalVolume domain.Money  `json:"total_volume"`
+	Users       []TopUser     `json:"users"`
+}
+
+// GetTopUsers mengambil leaderboard dari TopUsers, sedangkan total window
+// diambil dari agregat per status yang juga dipakai status breakdown
+func (s *DashboardService) GetTopUsers(ctx context.Context, q TopUsersQuery) (*TopUsers, error) {
+	w := q.Window
+	if w.Window == "" {
+		w.Window = WindowLast7Days
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = s.location
+	}
+
+	boundaries, err := s.summaryWindow(w, loc)
+	if err != nil {
+		return nil, err
+	}
+	from, to := boundaries[1], boundaries[2]
+
+	users, err := s.repo.TopUsers(ctx, domain.TopUsersFilter{
+		From:     from,
+		To:       to,
+		Currency: q.Currency,
+		By:       q.By,
+		Limit:    q.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.StatusVolumeByBucket(ctx, []time.Time{from, to})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TopUsers{
+		Window:      w.Window,
+		From:        from,
+		To:          to,
+		By:          q.By,
+		TotalVolume: domain.Money{Currency: q.Currency},
+		Users:       make([]TopUser, 0, len(users)),
+	}
+	for _, v := range volumes {
+		if v.Status.IsSettled() && v.Sum.Currency == q.Currency {
+			result.TotalCount += v.Count
+			result.TotalVolume.Minor += v.Net.Minor
+		}
+	}
+
+	for i, u := range users {
+		result.Users = append(result.Users, TopUser{
+			Rank:        i + 1,
+			UserID:      u.UserID,
+			Count:       u.Count,
+			Volume:      u.Volume,
+			VolumeShare: share(u.Volume.Minor, result.TotalVolume.Minor),
+			CountShare:  share(u.Count, result.TotalCount),
+		})
+	}
+
+	return result, nil
+}
+
+func share(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// defaultHistogramBins adalah jumlah bucket histogram jika BucketSize kosong
+const defaultHistogramBins = 10
+
+// DistributionQuery adalah parameter GetDistribution. BucketSize nol berarti
+// rentang min-max dibagi rata ke defaultHistogramBins bucket.
+type DistributionQuery struct {
+	Filter     domain.AmountFilter
+	BucketSize int64
+}
+
+// Percentiles dihitung dengan metode nearest-rank, jadi selalu berupa amount
+// transaksi yang benar-benar ada
+type Percentiles struct {
+	P50 domain.Money `json:"p50"`
+	P90 domain.Money `json:"p90"`
+	P95 domain.Money `json:"p95"`
+	P99 domain.Money `json:"p99"`
+}
+
+// HistogramBin berisi jumlah transaksi dengan amount di [From, To)
+type HistogramBin struct {
+	From  domain.Money `json:"from"`
+	To    domain.Money `json:"to"`
+	Count int64        `json:"count"`
+}
+
+type Distribution struct {
+	Currency    domain.Currency `json:"currency"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Count       int64           `json:"count"`
+	Min         domain.Money    `json:"min"`
+	Max         domain.Money    `json:"max"`
+	Percentiles Percentiles     `json:"percentiles"`
+	BucketSize  domain.Money    `json:"bucket_size"`
+	Histogram   []HistogramBin  `json:"histogram"`
+}
+
+// GetDistribution menghitung persentil dan histogram amount satu mata uang
+func (s *DashboardService) GetDistribution(ctx context.Context, q DistributionQuery) (*Distribution, error) {
+	f := q.Filter
+	if !f.From.Before(f.To) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	if q.BucketSize < 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	summary, err := s.repo.AmountSummary(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Distribution{
+		Currency: f.Currency,
+		From:     f.From,
+		To:       f.To,
+		Count:    summary.Count,
+		Min:      summary.Min,
+		Max:      summary.Max,
+		Percentiles: Percentiles{
+			P50: domain.Money{Currency: f.Currency},
+			P90: domain.Money{Currency: f.Currency},
+			P95: domain.Money{Currency: f.Currency},
+			P99: domain.Money{Currency: f.Currency},
+		},
+		BucketSize: domain.Money{Minor: q.BucketSize, Currency: f.Currency},
+		Histogram:  []HistogramBin{},
+	}
+	if summary.Count == 0 {
+		return result, nil
+	}
+
+	percentiles := []struct {
+		percentile float64
+		target     *domain.Money
+	}{
+		{50, &result.Percentiles.P50},
+		{90, &result.Percentiles.P90},
+		{95, &result.Percentiles.P95},
+		{99, &result.Percentiles.P99},
+	}
+	ranks := make([]int64, 0, len(percentiles))
+	for _, p := range percentiles {
+		ranks = append(ranks, domain.PercentileRank(p.percentile, summary.Count))
+	}
+
+	amounts, err := s.repo.AmountsAtRanks(ctx, f, ranks)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range percentiles {
+		*p.target = amounts[i]
+	}
+
+	size := q.BucketSize
+	if size == 0 {
+		// pembulatan ke atas supaya max tetap masuk bucket terakhir
+		size = (summary.Max.Minor - summary.Min.Minor + defaultHistogramBins) / defaultHistogramBins
+	}
+	result.BucketSize.Minor = size
+
+	boundaries, err := domain.HistogramBoundaries(summary.Min.Minor, summary.Max.Minor, size)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.AmountHistogram(ctx, f, boundaries)
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[int]int64, len(counts))
+	for _, c := range counts {
+		byBucket[c.Bucket] = c.Count
+	}
+
+	for i := 0; i < len(boundaries)-1; i++ {
+		result.Histogram = append(result.Histogram, HistogramBin{
+			From:  domain.Money{Minor: boundaries[i], Currency: f.Currency},
+			To:    domain.Money{Minor: boundaries[i+1], Currency: f.Currency},
+			Count: byBucket[i],
+		})
+	}
+
+	return result, nil
+}
+
+func containsCurrency(currencies []domain.Currency, c domain.Currency) bool {
+	for _, existing := range currencies {
+		if existing == c {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTotal adalah jumlah dan total amount kotor satu status per mata uang
+type StatusTotal struct {
+	Status domain.TransactionStatus `json:"status"`
+	Count  int64                    `json:"count"`
+	Total  domain.Money             `json:"total"`
+}
+
+// UserStats adalah statistik lifetime satu user. Average dan Largest hanya
+// menghitung transaksi settled, per mata uang.
+type UserStats struct {
+	UserID             uint           `json:"user_id"`
+	TransactionCount   int64          `json:"transaction_count"`
+	ByStatus           []StatusTotal  `json:"by_status"`
+	Average            []domain.Money `json:"average"`
+	Largest            []domain.Money `json:"largest"`
+	FirstTransactionAt *time.Time     `json:"first_transaction_at"`
+	LastTransactionAt  *time.Time     `json:"last_transaction_at"`
+	// SuccessRate adalah rasio 0-1 dari transaksi yang sudah punya hasil akhir
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// GetUserStats menghitung statistik user dari agregat per status
+func (s *DashboardService) GetUserStats(ctx context.Context, userID uint) (*UserStats, error) {
+	volumes, err := s.repo.UserVolumeByStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	first, last, err := s.repo.UserActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &UserStats{
+		UserID:             userID,
+		ByStatus:           make([]StatusTotal, 0, len(volumes)),
+		Average:            []domain.Money{},
+		Largest:            []domain.Money{},
+		FirstTransactionAt: first,
+		LastTransactionAt:  last,
+	}
+
+	counts := make(map[domain.TransactionStatus]int64)
+	currencies := make([]domain.Currency, 0)
+	settledNet := make(map[domain.Currency]domain.Money)
+	settledCount := make(map[domain.Currency]int64)
+	largest := make(map[domain.Currency]domain.Money)
+
+	for _, v := range volumes {
+		stats.TransactionCount += v.Count
+		counts[v.Status] += v.Count
+		stats.ByStatus = append(stats.ByStatus, StatusTotal{
+			Status: v.Status,
+			Count:  v.Count,
+			Total:  v.Sum,
+		})
+
+		if !v.Status.IsSettled() {
+			continue
+		}
+
+		currency := v.Sum.Currency
+		if !containsCurrency(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+		net := settledNet[currency]
+		settledNet[currency] = domain.Money{Minor: net.Minor + v.Net.Minor, Currency: currency}
+		settledCount[currency] += v.Count
+		if v.Largest.Minor > largest[currency].Minor {
+			largest[currency] = v.Largest
+		}
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	for _, currency := range currencies {
+		stats.Average = append(stats.Average, settledNet[currency].DivRound(settledCount[currency]))
+		stats.Largest = append(stats.Largest, largest[currency])
+	}
+	stats.SuccessRate = domain.SuccessRate(counts)
+
+	return stats, nil
+}
@@ -1,34 +1,59 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 	"transaction-technical-test/internal/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestDashboardService_GetSummary(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepo)
-	svc := NewDashboardService(mockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("TotalSuccessToday").Return(50000.0, nil).Once()
-		mockRepo.On("AverageAmountPerUser").Return(25000.0, nil).Once()
+		mockRepo.On("TotalSuccessToday", time.UTC).Return([]domain.Money{{Minor: 50000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTransactionAmount").Return([]domain.Money{{Minor: 25000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTotalPerUser").Return([]domain.Money{{Minor: 75000, Currency: domain.CurrencyIDR}}, nil).Once()
 		mockRepo.On("Latest", 10).Return([]domain.Transaction{{ID: 1}}, nil).Once()
+		mockRepo.On("StatusVolumeByBucket", mock.Anything).Return([]domain.BucketStatusVolume{}, nil).Once()
 
-		summary, err := svc.GetSummary()
+		summary, err := svc.GetSummary(ctx, SummaryQuery{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, summary)
-		assert.Equal(t, 50000.0, summary.TotalSuccessToday)
+		assert.Equal(t, []domain.Money{{Minor: 50000, Currency: domain.CurrencyIDR}}, summary.TotalSuccessToday)
+		assert.Equal(t, []domain.Money{{Minor: 25000, Currency: domain.CurrencyIDR}}, summary.AverageTransactionAmount)
+		assert.Equal(t, summary.AverageTransactionAmount, summary.AverageAmountPerUser)
+		assert.Equal(t, []domain.Money{{Minor: 75000, Currency: domain.CurrencyIDR}}, summary.AverageTotalPerUser)
+		assert.Equal(t, "UTC", summary.Timezone)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Explicit Timezone", func(t *testing.T) {
+		jakarta := time.FixedZone("WIB", 7*60*60)
+		mockRepo.On("TotalSuccessToday", jakarta).Return([]domain.Money{}, nil).Once()
+		mockRepo.On("AverageTransactionAmount").Return([]domain.Money{}, nil).Once()
+		mockRepo.On("AverageTotalPerUser").Return([]domain.Money{}, nil).Once()
+		mockRepo.On("Latest", 10).Return([]domain.Transaction{}, nil).Once()
+		mockRepo.On("StatusVolumeByBucket", mock.Anything).Return([]domain.BucketStatusVolume{}, nil).Once()
+
+		summary, err := svc.GetSummary(ctx, SummaryQuery{Location: jakarta})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "WIB", summary.Timezone)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Error on TotalSuccess", func(t *testing.T) {
-		mockRepo.On("TotalSuccessToday").Return(0.0, errors.New("db error")).Once()
+		mockRepo.On("TotalSuccessToday", time.UTC).Return(nil, errors.New("db error")).Once()
 
-		summary, err := svc.GetSummary()
+		summary, err := svc.GetSummary(ctx, SummaryQuery{})
 
 		assert.Error(t, err)
 		assert.Nil(t, summary)
@@ -36,25 +61,308 @@ func TestDashboardService_GetSummary(t *testing.T) {
 	})
 }
 func TestDashboardService_GetSummary_MoreErrors(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepo)
-	svc := NewDashboardService(mockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
 
 	t.Run("Error on AverageAmount", func(t *testing.T) {
-		mockRepo.On("TotalSuccessToday").Return(5000.0, nil).Once()
-		mockRepo.On("AverageAmountPerUser").Return(0.0, errors.New("error avg")).Once()
+		mockRepo.On("TotalSuccessToday", time.UTC).Return([]domain.Money{{Minor: 5000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTransactionAmount").Return(nil, errors.New("error avg")).Once()
+
+		res, err := svc.GetSummary(ctx, SummaryQuery{})
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Error on AverageTotalPerUser", func(t *testing.T) {
+		mockRepo.On("TotalSuccessToday", time.UTC).Return([]domain.Money{{Minor: 5000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTransactionAmount").Return([]domain.Money{{Minor: 2000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTotalPerUser").Return(nil, errors.New("error avg per user")).Once()
 
-		res, err := svc.GetSummary()
+		res, err := svc.GetSummary(ctx, SummaryQuery{})
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
 
 	t.Run("Error on Latest", func(t *testing.T) {
-		mockRepo.On("TotalSuccessToday").Return(5000.0, nil).Once()
-		mockRepo.On("AverageAmountPerUser").Return(2000.0, nil).Once()
+		mockRepo.On("TotalSuccessToday", time.UTC).Return([]domain.Money{{Minor: 5000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTransactionAmount").Return([]domain.Money{{Minor: 2000, Currency: domain.CurrencyIDR}}, nil).Once()
+		mockRepo.On("AverageTotalPerUser").Return([]domain.Money{{Minor: 2000, Currency: domain.CurrencyIDR}}, nil).Once()
 		mockRepo.On("Latest", 10).Return(nil, errors.New("error latest")).Once()
 
-		res, err := svc.GetSummary()
+		res, err := svc.GetSummary(ctx, SummaryQuery{})
 		assert.Error(t, err)
 		assert.Nil(t, res)
 	})
 }
+
+func TestDashboardService_GetSummary_StatusBreakdown(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
+	svc.now = func() time.Time { return time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC) }
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	volume := func(bucket int, status domain.TransactionStatus, count, minor int64) domain.BucketStatusVolume {
+		return domain.BucketStatusVolume{
+			Bucket:       bucket,
+			StatusVolume: domain.StatusVolume{Status: status, Count: count, Sum: idr(minor), Net: idr(minor), Largest: idr(minor)},
+		}
+	}
+
+	mockRepo.On("TotalSuccessToday", time.UTC).Return([]domain.Money{}, nil)
+	mockRepo.On("AverageTransactionAmount").Return([]domain.Money{}, nil)
+	mockRepo.On("AverageTotalPerUser").Return([]domain.Money{}, nil)
+	mockRepo.On("Latest", 10).Return([]domain.Transaction{}, nil)
+
+	t.Run("Last 7 Days", func(t *testing.T) {
+		// 7 hari terakhir termasuk hari ini: 4-10 Januari, dibanding 28 Des - 3 Jan
+		boundaries := []time.Time{time.Date(2023, 12, 28, 0, 0, 0, 0, time.UTC), day(4), day(11)}
+		mockRepo.On("StatusVolumeByBucket", boundaries).Return([]domain.BucketStatusVolume{
+			volume(0, domain.StatusFailed, 2, 2000),
+			volume(0, domain.StatusSuccess, 2, 5000),
+			volume(1, domain.StatusPending, 1, 100),
+			volume(1, domain.StatusSuccess, 3, 9000),
+		}, nil).Once()
+
+		summary, err := svc.GetSummary(ctx, SummaryQuery{Window: WindowLast7Days})
+
+		assert.NoError(t, err)
+		breakdown := summary.StatusBreakdown
+		assert.Equal(t, WindowLast7Days, breakdown.Window)
+		assert.True(t, breakdown.Current.From.Equal(day(4)))
+		assert.True(t, breakdown.Previous.To.Equal(day(4)))
+
+		// setiap status muncul di kedua periode supaya delta sejajar
+		assert.Equal(t, []StatusTotal{
+			{Status: domain.StatusFailed, Count: 0, Total: idr(0)},
+			{Status: domain.StatusPending, Count: 1, Total: idr(100)},
+			{Status: domain.StatusSuccess, Count: 3, Total: idr(9000)},
+		}, breakdown.Current.ByStatus)
+		assert.Equal(t, []StatusTotal{
+			{Status: domain.StatusFailed, Count: -2, Total: idr(-2000)},
+			{Status: domain.StatusPending, Count: 1, Total: idr(100)},
+			{Status: domain.StatusSuccess, Count: 1, Total: idr(4000)},
+		}, breakdown.Delta.ByStatus)
+
+		assert.Equal(t, int64(4), breakdown.Current.Count)
+		assert.Equal(t, int64(0), breakdown.Delta.Count)
+		assert.InDelta(t, 1.0, breakdown.Current.SuccessRate, 1e-9)
+		assert.InDelta(t, 0.5, breakdown.Previous.SuccessRate, 1e-9)
+		assert.InDelta(t, 0.5, breakdown.Delta.SuccessRate, 1e-9)
+	})
+
+	t.Run("Today", func(t *testing.T) {
+		boundaries := []time.Time{day(9), day(10), day(11)}
+		mockRepo.On("StatusVolumeByBucket", boundaries).Return([]domain.BucketStatusVolume{}, nil).Once()
+
+		summary, err := svc.GetSummary(ctx, SummaryQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, WindowToday, summary.StatusBreakdown.Window)
+		assert.Empty(t, summary.StatusBreakdown.Current.ByStatus)
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		boundaries := []time.Time{day(1), day(3), day(5)}
+		mockRepo.On("StatusVolumeByBucket", boundaries).Return([]domain.BucketStatusVolume{}, nil).Once()
+
+		_, err := svc.GetSummary(ctx, SummaryQuery{Window: WindowCustom, From: day(3), To: day(5)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Custom Invalid Range", func(t *testing.T) {
+		_, err := svc.GetSummary(ctx, SummaryQuery{Window: WindowCustom, From: day(5), To: day(3)})
+		assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+	})
+
+	t.Run("Unknown Window", func(t *testing.T) {
+		_, err := svc.GetSummary(ctx, SummaryQuery{Window: "30d"})
+		assert.ErrorIs(t, err, domain.ErrInvalidWindow)
+	})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDashboardService_GetTimeseries(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	boundaries := []time.Time{day(1), day(2), day(3), day(4)}
+	usd := domain.Money{Minor: 500, Currency: domain.CurrencyUSD}
+
+	mockRepo.On("VolumeByBucket", boundaries, (*domain.TransactionStatus)(nil)).Return([]domain.BucketVolume{
+		{Bucket: 0, Count: 3, Sum: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}},
+		{Bucket: 2, Count: 1, Sum: usd},
+	}, nil).Once()
+
+	series, err := svc.GetTimeseries(ctx, TimeseriesQuery{From: day(1), To: day(4), Interval: domain.IntervalDay})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", series.Timezone)
+	assert.Len(t, series.Buckets, 3)
+
+	first := series.Buckets[0]
+	assert.Equal(t, int64(3), first.Count)
+	assert.Equal(t, []domain.Money{{Minor: 1000, Currency: domain.CurrencyIDR}, {Currency: domain.CurrencyUSD}}, first.Sum)
+	assert.Equal(t, []domain.Money{{Minor: 333, Currency: domain.CurrencyIDR}, {Currency: domain.CurrencyUSD}}, first.Average)
+
+	// bucket kosong tetap ada dengan nilai nol untuk setiap mata uang
+	empty := series.Buckets[1]
+	assert.Equal(t, int64(0), empty.Count)
+	assert.Equal(t, []domain.Money{{Currency: domain.CurrencyIDR}, {Currency: domain.CurrencyUSD}}, empty.Sum)
+
+	assert.Equal(t, []domain.Money{{Currency: domain.CurrencyIDR}, usd}, series.Buckets[2].Sum)
+	mockRepo.AssertExpectations(t)
+
+	t.Run("Invalid Range", func(t *testing.T) {
+		_, err := svc.GetTimeseries(ctx, TimeseriesQuery{From: day(4), To: day(1), Interval: domain.IntervalDay})
+		assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+	})
+}
+
+func TestDashboardService_GetUserStats(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
+
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("UserVolumeByStatus", uint(7)).Return([]domain.StatusVolume{
+		{Status: domain.StatusFailed, Count: 1, Sum: idr(9000), Net: idr(9000), Largest: idr(9000)},
+		{Status: domain.StatusPartiallyRefunded, Count: 1, Sum: idr(4000), Net: idr(3000), Largest: idr(4000)},
+		{Status: domain.StatusPending, Count: 2, Sum: idr(2000), Net: idr(2000), Largest: idr(1000)},
+		{Status: domain.StatusRefunded, Count: 1, Sum: idr(5000), Net: idr(0), Largest: idr(5000)},
+		{Status: domain.StatusSuccess, Count: 2, Sum: idr(3000), Net: idr(3000), Largest: idr(2000)},
+	}, nil).Once()
+	mockRepo.On("UserActivity", uint(7)).Return(&first, &last, nil).Once()
+
+	stats, err := svc.GetUserStats(ctx, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), stats.TransactionCount)
+	assert.Len(t, stats.ByStatus, 5)
+	// settled: partially_refunded (net 3000) + 2 success (3000) = 6000 / 3
+	assert.Equal(t, []domain.Money{idr(2000)}, stats.Average)
+	// refunded dan failed tidak dihitung sebagai largest
+	assert.Equal(t, []domain.Money{idr(4000)}, stats.Largest)
+	// berhasil 4 (success, partially_refunded, refunded) dari 5 yang sudah final
+	assert.InDelta(t, 0.8, stats.SuccessRate, 1e-9)
+	assert.Equal(t, &first, stats.FirstTransactionAt)
+	assert.Equal(t, &last, stats.LastTransactionAt)
+	mockRepo.AssertExpectations(t)
+
+	t.Run("No Transactions", func(t *testing.T) {
+		mockRepo.On("UserVolumeByStatus", uint(8)).Return([]domain.StatusVolume{}, nil).Once()
+		mockRepo.On("UserActivity", uint(8)).Return(nil, nil, nil).Once()
+
+		stats, err := svc.GetUserStats(ctx, 8)
+
+		assert.NoError(t, err)
+		assert.Zero(t, stats.TransactionCount)
+		assert.Zero(t, stats.SuccessRate)
+		assert.Empty(t, stats.Average)
+		assert.Nil(t, stats.FirstTransactionAt)
+	})
+}
+
+func TestDashboardService_GetDistribution(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
+
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	filter := domain.AmountFilter{
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Currency: domain.CurrencyIDR,
+	}
+
+	t.Run("Default Bucket Size", func(t *testing.T) {
+		mockRepo.On("AmountSummary", filter).Return(domain.AmountSummary{Count: 20, Min: idr(100), Max: idr(1090)}, nil).Once()
+		mockRepo.On("AmountsAtRanks", filter, []int64{10, 18, 19, 20}).
+			Return([]domain.Money{idr(500), idr(900), idr(950), idr(1090)}, nil).Once()
+		// (1090 - 100 + 10) / 10 = 100 per bucket, 100 sampai 1100
+		boundaries := []int64{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1100}
+		mockRepo.On("AmountHistogram", filter, boundaries).Return([]domain.HistogramCount{
+			{Bucket: 0, Count: 15},
+			{Bucket: 9, Count: 5},
+		}, nil).Once()
+
+		dist, err := svc.GetDistribution(ctx, DistributionQuery{Filter: filter})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Percentiles{P50: idr(500), P90: idr(900), P95: idr(950), P99: idr(1090)}, dist.Percentiles)
+		assert.Equal(t, idr(100), dist.BucketSize)
+		assert.Len(t, dist.Histogram, 10)
+		assert.Equal(t, HistogramBin{From: idr(100), To: idr(200), Count: 15}, dist.Histogram[0])
+		assert.Equal(t, int64(0), dist.Histogram[5].Count)
+		assert.Equal(t, int64(5), dist.Histogram[9].Count)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("No Transactions", func(t *testing.T) {
+		mockRepo.On("AmountSummary", filter).Return(domain.AmountSummary{Min: idr(0), Max: idr(0)}, nil).Once()
+
+		dist, err := svc.GetDistribution(ctx, DistributionQuery{Filter: filter, BucketSize: 1000})
+
+		assert.NoError(t, err)
+		assert.Zero(t, dist.Count)
+		assert.Equal(t, idr(0), dist.Percentiles.P50)
+		assert.Empty(t, dist.Histogram)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Range", func(t *testing.T) {
+		inverted := filter
+		inverted.From, inverted.To = filter.To, filter.From
+
+		_, err := svc.GetDistribution(ctx, DistributionQuery{Filter: inverted})
+		assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+	})
+}
+
+func TestDashboardService_GetTopUsers(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewDashboardService(mockRepo, time.UTC)
+	svc.now = func() time.Time { return time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC) }
+
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	from := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("TopUsers", domain.TopUsersFilter{
+		From: from, To: to, Currency: domain.CurrencyIDR, By: domain.RankByVolume, Limit: 2,
+	}).Return([]domain.UserVolume{
+		{UserID: 9, Count: 1, Volume: idr(6000)},
+		{UserID: 4, Count: 3, Volume: idr(3000)},
+	}, nil).Once()
+	mockRepo.On("StatusVolumeByBucket", []time.Time{from, to}).Return([]domain.BucketStatusVolume{
+		{StatusVolume: domain.StatusVolume{Status: domain.StatusSuccess, Count: 4, Sum: idr(8000), Net: idr(8000)}},
+		{StatusVolume: domain.StatusVolume{Status: domain.StatusPartiallyRefunded, Count: 1, Sum: idr(4000), Net: idr(2000)}},
+		// failed dan mata uang lain tidak dihitung ke total
+		{StatusVolume: domain.StatusVolume{Status: domain.StatusFailed, Count: 7, Sum: idr(9000), Net: idr(9000)}},
+		{StatusVolume: domain.StatusVolume{
+			Status: domain.StatusSuccess, Count: 1,
+			Sum: domain.Money{Minor: 1, Currency: domain.CurrencyUSD}, Net: domain.Money{Minor: 1, Currency: domain.CurrencyUSD},
+		}},
+	}, nil).Once()
+
+	top, err := svc.GetTopUsers(ctx, TopUsersQuery{Currency: domain.CurrencyIDR, By: domain.RankByVolume, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, WindowLast7Days, top.Window)
+	assert.Equal(t, int64(5), top.TotalCount)
+	assert.Equal(t, idr(10000), top.TotalVolume)
+	assert.Equal(t, []TopUser{
+		{Rank: 1, UserID: 9, Count: 1, Volume: idr(6000), VolumeShare: 0.6, CountShare: 0.2},
+		{Rank: 2, UserID: 4, Count: 3, Volume: idr(3000), VolumeShare: 0.3, CountShare: 0.6},
+	}, top.Users)
+	mockRepo.AssertExpectations(t)
+}
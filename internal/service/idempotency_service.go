@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type IdempotencyService struct {
+	repo domain.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func NewIdempotencyService(repo domain.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		repo: repo,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Begin mendaftarkan key untuk request baru.
+// Jika key belum pernah dipakai, hasilnya (nil, nil) dan request boleh diproses.
+// Jika key sudah selesai diproses dengan request yang sama, record lama
+// dikembalikan untuk di-replay.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	now := s.now()
+	rec := &domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	reserved, err := s.repo.Reserve(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	existing, err := s.repo.FindByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			// Dihapus oleh request lain di antara Reserve dan FindByKey
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+
+	if existing.IsExpired(now) {
+		if err := s.repo.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+
+		reserved, err := s.repo.Reserve(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved {
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+		return nil, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, domain.ErrIdempotencyKeyMismatch
+	}
+
+	if !existing.IsCompleted() {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+// Complete menyimpan response asli untuk key yang sudah di-Begin
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	return s.repo.Complete(ctx, key, statusCode, body)
+}
+
+// Release melepas key jika request gagal, supaya client bisa retry
+func (s *IdempotencyService) Release(ctx context.Context, key string) error {
+	return s.repo.Delete(ctx, key)
+}
+
+// PurgeExpired menghapus semua key yang sudah melewati TTL
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, s.now())
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepo struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepo) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (bool, error) {
+	args := m.Called(rec)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepo) FindByKey(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepo) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	args := m.Called(key, statusCode, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepo) Delete(ctx context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	newService := func() (*IdempotencyService, *MockIdempotencyRepo) {
+		repo := new(MockIdempotencyRepo)
+		svc := NewIdempotencyService(repo, time.Hour)
+		svc.now = func() time.Time { return now }
+		return svc, repo
+	}
+
+	t.Run("New Key", func(t *testing.T) {
+		svc, repo := newService()
+		repo.On("Reserve", mock.MatchedBy(func(rec *domain.IdempotencyRecord) bool {
+			return rec.Key == "k" && rec.ExpiresAt.Equal(now.Add(time.Hour))
+		})).Return(true, nil).Once()
+
+		rec, err := svc.Begin(context.Background(), "k", "fp")
+		assert.NoError(t, err)
+		assert.Nil(t, rec)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		svc, repo := newService()
+		stored := &domain.IdempotencyRecord{Key: "k", Fingerprint: "fp", StatusCode: 201, ExpiresAt: now.Add(time.Minute)}
+		repo.On("Reserve", mock.Anything).Return(false, nil).Once()
+		repo.On("FindByKey", "k").Return(stored, nil).Once()
+
+		rec, err := svc.Begin(context.Background(), "k", "fp")
+		assert.NoError(t, err)
+		assert.Equal(t, stored, rec)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		svc, repo := newService()
+		stored := &domain.IdempotencyRecord{Key: "k", Fingerprint: "other", StatusCode: 201, ExpiresAt: now.Add(time.Minute)}
+		repo.On("Reserve", mock.Anything).Return(false, nil).Once()
+		repo.On("FindByKey", "k").Return(stored, nil).Once()
+
+		_, err := svc.Begin(context.Background(), "k", "fp")
+		assert.ErrorIs(t, err, domain.ErrIdempotencyKeyMismatch)
+	})
+
+	t.Run("In Progress", func(t *testing.T) {
+		svc, repo := newService()
+		stored := &domain.IdempotencyRecord{Key: "k", Fingerprint: "fp", ExpiresAt: now.Add(time.Minute)}
+		repo.On("Reserve", mock.Anything).Return(false, nil).Once()
+		repo.On("FindByKey", "k").Return(stored, nil).Once()
+
+		_, err := svc.Begin(context.Background(), "k", "fp")
+		assert.ErrorIs(t, err, domain.ErrIdempotencyKeyInProgress)
+	})
+
+	t.Run("Expired Key Is Reused", func(t *testing.T) {
+		svc, repo := newService()
+		stored := &domain.IdempotencyRecord{Key: "k", Fingerprint: "other", StatusCode: 201, ExpiresAt: now.Add(-time.Minute)}
+		repo.On("Reserve", mock.Anything).Return(false, nil).Once()
+		repo.On("FindByKey", "k").Return(stored, nil).Once()
+		repo.On("Delete", "k").Return(nil).Once()
+		repo.On("Reserve", mock.Anything).Return(true, nil).Once()
+
+		rec, err := svc.Begin(context.Background(), "k", "fp")
+		assert.NoError(t, err)
+		assert.Nil(t, rec)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"transaction-technical-test/internal/domain"
+)
+
+// AccountBalance adalah saldo akun ledger, positif berarti saldo debit
+type AccountBalance struct {
+	Account domain.Account `json:"account"`
+	Balance domain.Money   `json:"balance"`
+}
+
+// LedgerCheck adalah hasil pengecekan invariant double-entry
+type LedgerCheck struct {
+	Balanced bool           `json:"balanced"`
+	Totals   []domain.Money `json:"totals"`
+}
+
+type LedgerService struct {
+	repo domain.LedgerRepository
+}
+
+func NewLedgerService(repo domain.LedgerRepository) *LedgerService {
+	return &LedgerService{
+		repo: repo,
+	}
+}
+
+// RecordPayment mencatat transaksi success: debit wallet user, kredit settlement.
+// Dipanggil di dalam Transactor yang sama dengan perubahan status.
+func (s *LedgerService) RecordPayment(ctx context.Context, tx *domain.Transaction) error {
+	wallet, settlement, err := s.paymentAccounts(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	entry, err := domain.NewJournalEntry(
+		tx.ID,
+		fmt.Sprintf("payment for transaction #%d", tx.ID),
+		[]domain.Posting{
+			domain.Debit(wallet.ID, tx.Amount),
+			domain.Credit(settlement.ID, tx.Amount),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CreateEntry(ctx, entry)
+}
+
+// RecordRefund membalik posting payment sebesar amount refund
+func (s *LedgerService) RecordRefund(ctx context.Context, tx *domain.Transaction, refund *domain.Refund) error {
+	wallet, settlement, err := s.paymentAccounts(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	entry, err := domain.NewJournalEntry(
+		tx.ID,
+		fmt.Sprintf("refund #%d for transaction #%d", refund.ID, tx.ID),
+		[]domain.Posting{
+			domain.Debit(settlement.ID, refund.Amount),
+			domain.Credit(wallet.ID, refund.Amount),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CreateEntry(ctx, entry)
+}
+
+// GetAccountBalance ambil saldo akun ledger
+func (s *LedgerService) GetAccountBalance(ctx context.Context, accountID uint) (*AccountBalance, error) {
+	account, err := s.repo.FindAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.repo.AccountBalance(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountBalance{
+		Account: *account,
+		Balance: domain.Money{Minor: balance, Currency: account.Currency},
+	}, nil
+}
+
+// CheckInvariant memastikan jumlah seluruh posting per mata uang adalah nol
+func (s *LedgerService) CheckInvariant(ctx context.Context) (*LedgerCheck, error) {
+	totals, err := s.repo.PostingTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &LedgerCheck{Balanced: true, Totals: totals}
+	for _, total := range totals {
+		if total.Minor != 0 {
+			check.Balanced = false
+		}
+	}
+
+	return check, nil
+}
+
+func (s *LedgerService) paymentAccounts(ctx context.Context, tx *domain.Transaction) (*domain.Account, *domain.Account, error) {
+	currency := tx.Amount.Currency
+
+	wallet, err := s.repo.FindOrCreateAccount(ctx,
+		domain.UserWalletAccountCode(tx.UserID, currency),
+		domain.AccountTypeUserWallet,
+		currency,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settlement, err := s.repo.FindOrCreateAccount(ctx,
+		domain.SettlementAccountCode(currency),
+		domain.AccountTypeSettlement,
+		currency,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wallet, settlement, nil
+}
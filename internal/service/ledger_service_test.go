@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerService_PaymentAndRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeLedgerRepo{}
+	svc := NewLedgerService(repo)
+
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+	tx := &domain.Transaction{ID: 1, UserID: 5, Amount: idr(10000), Status: domain.StatusSuccess}
+
+	assert.NoError(t, svc.RecordPayment(ctx, tx))
+	assert.NoError(t, svc.RecordRefund(ctx, tx, &domain.Refund{ID: 1, TransactionID: 1, Amount: idr(2500)}))
+
+	wallet, err := svc.GetAccountBalance(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UserWalletAccountCode(5, domain.CurrencyIDR), wallet.Account.Code)
+	assert.Equal(t, idr(7500), wallet.Balance)
+
+	settlement, err := svc.GetAccountBalance(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, idr(-7500), settlement.Balance)
+
+	check, err := svc.CheckInvariant(ctx)
+	assert.NoError(t, err)
+	assert.True(t, check.Balanced)
+}
+
+func TestLedgerService_CheckInvariant_Imbalanced(t *testing.T) {
+	repo := &fakeLedgerRepo{
+		entries: []domain.JournalEntry{{
+			Postings: []domain.Posting{{AccountID: 1, Amount: domain.Money{Minor: 10, Currency: domain.CurrencyIDR}}},
+		}},
+	}
+	svc := NewLedgerService(repo)
+
+	check, err := svc.CheckInvariant(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, check.Balanced)
+}
+
+func TestLedgerService_GetAccountBalance_NotFound(t *testing.T) {
+	svc := NewLedgerService(&fakeLedgerRepo{})
+
+	_, err := svc.GetAccountBalance(context.Background(), 99)
+	assert.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
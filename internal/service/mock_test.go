@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"time"
 	"transaction-technical-test/internal/domain"
 
 	"github.com/stretchr/testify/mock"
@@ -11,13 +13,13 @@ type MockRepo struct {
 	mock.Mock
 }
 
-// Implementasi fungsi-fungsi interface repository
-func (m *MockRepo) Create(tx *domain.Transaction) error {
+// Implementasi fungsi-fungsi interface repository, context tidak ikut dicatat
+func (m *MockRepo) Create(ctx context.Context, tx *domain.Transaction) error {
 	args := m.Called(tx)
 	return args.Error(0)
 }
 
-func (m *MockRepo) FindByID(id uint) (*domain.Transaction, error) {
+func (m *MockRepo) FindByID(ctx context.Context, id uint) (*domain.Transaction, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -25,7 +27,15 @@ func (m *MockRepo) FindByID(id uint) (*domain.Transaction, error) {
 	return args.Get(0).(*domain.Transaction), args.Error(1)
 }
 
-func (m *MockRepo) FindAll(filter domain.TransactionFilter) ([]domain.Transaction, error) {
+func (m *MockRepo) FindByIDForUpdate(ctx context.Context, id uint) (*domain.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockRepo) FindAll(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
 	args := m.Called(filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,30 +43,261 @@ func (m *MockRepo) FindAll(filter domain.TransactionFilter) ([]domain.Transactio
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
-func (m *MockRepo) Update(tx *domain.Transaction) error {
+func (m *MockRepo) Count(ctx context.Context, filter domain.TransactionFilter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepo) Update(ctx context.Context, tx *domain.Transaction) error {
 	args := m.Called(tx)
 	return args.Error(0)
 }
 
-func (m *MockRepo) Delete(id uint) error {
+func (m *MockRepo) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockRepo) TotalSuccessToday() (float64, error) {
-	args := m.Called()
-	return args.Get(0).(float64), args.Error(1)
+func (m *MockRepo) FindExpiredAuthorizations(ctx context.Context, now time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
-func (m *MockRepo) AverageAmountPerUser() (float64, error) {
+func (m *MockRepo) Restore(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepo) History(ctx context.Context, id uint) ([]domain.TransactionEvent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransactionEvent), args.Error(1)
+}
+
+func (m *MockRepo) TotalSuccessToday(ctx context.Context, loc *time.Location) ([]domain.Money, error) {
+	args := m.Called(loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Money), args.Error(1)
+}
+
+func (m *MockRepo) AverageTransactionAmount(ctx context.Context) ([]domain.Money, error) {
 	args := m.Called()
-	return args.Get(0).(float64), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Money), args.Error(1)
 }
 
-func (m *MockRepo) Latest(limit int) ([]domain.Transaction, error) {
+func (m *MockRepo) Latest(ctx context.Context, limit int) ([]domain.Transaction, error) {
 	args := m.Called(limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
+
+func (m *MockRepo) VolumeByBucket(ctx context.Context, boundaries []time.Time, status *domain.TransactionStatus) ([]domain.BucketVolume, error) {
+	args := m.Called(boundaries, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BucketVolume), args.Error(1)
+}
+
+func (m *MockRepo) StatusVolumeByBucket(ctx context.Context, boundaries []time.Time) ([]domain.BucketStatusVolume, error) {
+	args := m.Called(boundaries)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BucketStatusVolume), args.Error(1)
+}
+
+func (m *MockRepo) AmountSummary(ctx context.Context, filter domain.AmountFilter) (domain.AmountSummary, error) {
+	args := m.Called(filter)
+	return args.Get(0).(domain.AmountSummary), args.Error(1)
+}
+
+func (m *MockRepo) AmountsAtRanks(ctx context.Context, filter domain.AmountFilter, ranks []int64) ([]domain.Money, error) {
+	args := m.Called(filter, ranks)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Money), args.Error(1)
+}
+
+func (m *MockRepo) AmountHistogram(ctx context.Context, filter domain.AmountFilter, boundaries []int64) ([]domain.HistogramCount, error) {
+	args := m.Called(filter, boundaries)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.HistogramCount), args.Error(1)
+}
+
+func (m *MockRepo) TopUsers(ctx context.Context, filter domain.TopUsersFilter) ([]domain.UserVolume, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.UserVolume), args.Error(1)
+}
+
+func (m *MockRepo) AverageTotalPerUser(ctx context.Context) ([]domain.Money, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Money), args.Error(1)
+}
+
+func (m *MockRepo) UserVolumeByStatus(ctx context.Context, userID uint) ([]domain.StatusVolume, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StatusVolume), args.Error(1)
+}
+
+func (m *MockRepo) UserActivity(ctx context.Context, userID uint) (*time.Time, *time.Time, error) {
+	args := m.Called(userID)
+	first, _ := args.Get(0).(*time.Time)
+	last, _ := args.Get(1).(*time.Time)
+	return first, last, args.Error(2)
+}
+
+// MockRefundRepo tiruan dari RefundRepository
+type MockRefundRepo struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepo) Create(ctx context.Context, refund *domain.Refund) error {
+	args := m.Called(refund)
+	return args.Error(0)
+}
+
+func (m *MockRefundRepo) FindByTransactionID(ctx context.Context, transactionID uint) ([]domain.Refund, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Refund), args.Error(1)
+}
+
+// fakeTransactor menjalankan fn langsung tanpa database transaction
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeLedgerRepo adalah LedgerRepository in-memory untuk test
+type fakeLedgerRepo struct {
+	accounts []domain.Account
+	entries  []domain.JournalEntry
+}
+
+func (f *fakeLedgerRepo) FindOrCreateAccount(ctx context.Context, code string, accountType domain.AccountType, currency domain.Currency) (*domain.Account, error) {
+	for i := range f.accounts {
+		if f.accounts[i].Code == code {
+			return &f.accounts[i], nil
+		}
+	}
+	f.accounts = append(f.accounts, domain.Account{
+		ID:       uint(len(f.accounts) + 1),
+		Code:     code,
+		Type:     accountType,
+		Currency: currency,
+	})
+	return &f.accounts[len(f.accounts)-1], nil
+}
+
+func (f *fakeLedgerRepo) FindAccountByID(ctx context.Context, id uint) (*domain.Account, error) {
+	for i := range f.accounts {
+		if f.accounts[i].ID == id {
+			return &f.accounts[i], nil
+		}
+	}
+	return nil, domain.ErrAccountNotFound
+}
+
+func (f *fakeLedgerRepo) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	entry.ID = uint(len(f.entries) + 1)
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+func (f *fakeLedgerRepo) AccountBalance(ctx context.Context, accountID uint) (int64, error) {
+	var balance int64
+	for _, e := range f.entries {
+		for _, p := range e.Postings {
+			if p.AccountID == accountID {
+				balance += p.Amount.Minor
+			}
+		}
+	}
+	return balance, nil
+}
+
+func (f *fakeLedgerRepo) PostingTotals(ctx context.Context) ([]domain.Money, error) {
+	totals := map[domain.Currency]int64{}
+	for _, e := range f.entries {
+		for _, p := range e.Postings {
+			totals[p.Amount.Currency] += p.Amount.Minor
+		}
+	}
+
+	result := make([]domain.Money, 0, len(totals))
+	for currency, total := range totals {
+		result = append(result, domain.Money{Minor: total, Currency: currency})
+	}
+	return result, nil
+}
+
+// fakeWalletRepo adalah WalletRepository in-memory untuk test
+type fakeWalletRepo struct {
+	wallets []domain.Wallet
+}
+
+func (f *fakeWalletRepo) FindForUpdate(ctx context.Context, userID uint, currency domain.Currency) (*domain.Wallet, error) {
+	for i := range f.wallets {
+		if f.wallets[i].UserID == userID && f.wallets[i].Balance.Currency == currency {
+			wallet := f.wallets[i]
+			return &wallet, nil
+		}
+	}
+
+	wallet := domain.NewWallet(userID, currency)
+	wallet.ID = uint(len(f.wallets) + 1)
+	f.wallets = append(f.wallets, *wallet)
+	return wallet, nil
+}
+
+func (f *fakeWalletRepo) FindByUserID(ctx context.Context, userID uint) ([]domain.Wallet, error) {
+	var result []domain.Wallet
+	for _, w := range f.wallets {
+		if w.UserID == userID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeWalletRepo) Update(ctx context.Context, wallet *domain.Wallet) error {
+	for i := range f.wallets {
+		if f.wallets[i].ID == wallet.ID {
+			f.wallets[i] = *wallet
+		}
+	}
+	return nil
+}
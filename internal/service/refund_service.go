@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type RefundService struct {
+	transactor   domain.Transactor
+	transactions domain.TransactionRepository
+	refunds      domain.RefundRepository
+	ledger       *LedgerService
+	wallets      *WalletService
+	feed         *Broadcaster
+}
+
+func NewRefundService(
+	transactor domain.Transactor,
+	transactions domain.TransactionRepository,
+	refunds domain.RefundRepository,
+	ledger *LedgerService,
+	wallets *WalletService,
+	feed *Broadcaster,
+) *RefundService {
+	return &RefundService{
+		transactor:   transactor,
+		transactions: transactions,
+		refunds:      refunds,
+		ledger:       ledger,
+		wallets:      wallets,
+		feed:         feed,
+	}
+}
+
+// Create refund untuk transaksi. amount dalam satuan mayor (misal "1000.50")
+// dengan mata uang transaksi; amount kosong berarti refund seluruh sisa dan
+// currency kosong berarti mata uang transaksi.
+// Transaksi dikunci selama proses supaya refund paralel tidak melebihi amount.
+// Amount refund dikembalikan ke wallet user dan status transaksi yang baru
+// di-publish ke live feed setelah commit.
+func (s *RefundService) Create(
+	ctx context.Context,
+	transactionID uint,
+	amount string,
+	currency domain.Currency,
+	reason string,
+) (*domain.Refund, error) {
+	var (
+		refund    *domain.Refund
+		tx        *domain.Transaction
+		oldStatus domain.TransactionStatus
+	)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		tx, err = s.transactions.FindByIDForUpdate(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+		oldStatus = tx.Status
+
+		if currency != "" && currency != tx.Amount.Currency {
+			return domain.ErrCurrencyMismatch
+		}
+
+		refundAmount := tx.RefundableAmount()
+		if amount != "" {
+			refundAmount, err = domain.ParseMoney(amount, tx.Amount.Currency)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Refund(refundAmount); err != nil {
+			return err
+		}
+
+		refund = domain.NewRefund(tx.ID, refundAmount, reason)
+		if err := s.refunds.Create(ctx, refund); err != nil {
+			return err
+		}
+
+		if err := s.transactions.Update(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.wallets.Credit(ctx, tx.UserID, refundAmount); err != nil {
+			return err
+		}
+
+		return s.ledger.RecordRefund(ctx, tx, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishUpdated(ctx, s.feed, *tx, oldStatus)
+
+	return refund, nil
+}
+
+// GetByTransactionID ambil semua refund milik transaksi
+func (s *RefundService) GetByTransactionID(ctx context.Context, transactionID uint) ([]domain.Refund, error) {
+	if _, err := s.transactions.FindByID(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	return s.refunds.FindByTransactionID(ctx, transactionID)
+}
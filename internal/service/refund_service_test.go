@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestRefundService_Create(t *testing.T) {
+	ctx := context.Background()
+	idr := func(minor int64) domain.Money { return domain.Money{Minor: minor, Currency: domain.CurrencyIDR} }
+
+	t.Run("Partial Refund", func(t *testing.T) {
+		txRepo, refundRepo, walletRepo := new(MockRepo), new(MockRefundRepo), &fakeWalletRepo{}
+		svc := NewRefundService(fakeTransactor{}, txRepo, refundRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(walletRepo), NewBroadcaster(1, zap.NewNop()))
+
+		tx := &domain.Transaction{ID: 1, UserID: 4, Amount: idr(100000), Refunded: idr(0), Status: domain.StatusSuccess}
+		txRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+		refundRepo.On("Create", mock.Anything).Return(nil).Once()
+		txRepo.On("Update", mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Status == domain.StatusPartiallyRefunded && tx.Refunded.Minor == 25050
+		})).Return(nil).Once()
+
+		refund, err := svc.Create(ctx, 1, "250.50", "", "customer request")
+
+		assert.NoError(t, err)
+		assert.Equal(t, idr(25050), refund.Amount)
+		assert.Equal(t, "customer request", refund.Reason)
+		assert.Equal(t, idr(25050), walletRepo.wallets[0].Balance)
+		txRepo.AssertExpectations(t)
+		refundRepo.AssertExpectations(t)
+	})
+
+	t.Run("Full Refund When Amount Empty", func(t *testing.T) {
+		txRepo, refundRepo := new(MockRepo), new(MockRefundRepo)
+		svc := NewRefundService(fakeTransactor{}, txRepo, refundRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
+
+		tx := &domain.Transaction{ID: 1, Amount: idr(100000), Refunded: idr(40000), Status: domain.StatusPartiallyRefunded}
+		txRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+		refundRepo.On("Create", mock.Anything).Return(nil).Once()
+		txRepo.On("Update", mock.Anything).Return(nil).Once()
+
+		refund, err := svc.Create(ctx, 1, "", "", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, idr(60000), refund.Amount)
+		assert.Equal(t, domain.StatusRefunded, tx.Status)
+	})
+
+	t.Run("Pending Transaction Rejected", func(t *testing.T) {
+		txRepo, refundRepo := new(MockRepo), new(MockRefundRepo)
+		svc := NewRefundService(fakeTransactor{}, txRepo, refundRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
+
+		tx := &domain.Transaction{ID: 1, Amount: idr(100000), Status: domain.StatusPending}
+		txRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+
+		_, err := svc.Create(ctx, 1, "10", "", "")
+
+		assert.ErrorIs(t, err, domain.ErrNotRefundable)
+		refundRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Currency Mismatch", func(t *testing.T) {
+		txRepo, refundRepo := new(MockRepo), new(MockRefundRepo)
+		svc := NewRefundService(fakeTransactor{}, txRepo, refundRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
+
+		tx := &domain.Transaction{ID: 1, Amount: idr(100000), Status: domain.StatusSuccess}
+		txRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+
+		_, err := svc.Create(ctx, 1, "10", domain.CurrencyUSD, "")
+
+		assert.ErrorIs(t, err, domain.ErrCurrencyMismatch)
+	})
+}
+
+func TestRefundService_GetByTransactionID(t *testing.T) {
+	ctx := context.Background()
+	txRepo, refundRepo := new(MockRepo), new(MockRefundRepo)
+	svc := NewRefundService(fakeTransactor{}, txRepo, refundRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
+
+	t.Run("Not Found", func(t *testing.T) {
+		txRepo.On("FindByID", uint(9)).Return(nil, domain.ErrTransactionNotFound).Once()
+
+		_, err := svc.GetByTransactionID(ctx, 9)
+		assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		txRepo.On("FindByID", uint(1)).Return(&domain.Transaction{ID: 1}, nil).Once()
+		refundRepo.On("FindByTransactionID", uint(1)).Return([]domain.Refund{{ID: 1}}, nil).Once()
+
+		refunds, err := svc.GetByTransactionID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, refunds, 1)
+	})
+}
@@ -1,53 +1,212 @@
 package service
 
-import "transaction-technical-test/internal/domain"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"transaction-technical-test/internal/domain"
+)
 
 type TransactionService struct {
-	repo domain.TransactionRepository
+	transactor domain.Transactor
+	repo       domain.TransactionRepository
+	ledger     *LedgerService
+	wallets    *WalletService
+	feed       *Broadcaster
 }
 
-func NewTransactionService(repo domain.TransactionRepository) *TransactionService {
+// NewTransactionService menerima feed untuk live feed dashboard; transaksi
+// yang dibuat atau berubah status di-publish setelah commit
+func NewTransactionService(
+	transactor domain.Transactor,
+	repo domain.TransactionRepository,
+	ledger *LedgerService,
+	wallets *WalletService,
+	feed *Broadcaster,
+) *TransactionService {
 	return &TransactionService{
-		repo: repo,
+		transactor: transactor,
+		repo:       repo,
+		ledger:     ledger,
+		wallets:    wallets,
+		feed:       feed,
 	}
 }
 
 // Create transaksi baru
-func (s *TransactionService) Create(userID uint, amount float64) (*domain.Transaction, error) {
+func (s *TransactionService) Create(ctx context.Context, userID uint, amount domain.Money) (*domain.Transaction, error) {
+	if !amount.Currency.IsValid() {
+		return nil, domain.ErrInvalidCurrency
+	}
+	if !amount.IsPositive() {
+		return nil, domain.ErrInvalidAmount
+	}
+
 	tx := domain.NewTransaction(userID, amount)
 
-	if err := s.repo.Create(tx); err != nil {
+	if err := s.repo.Create(ctx, tx); err != nil {
 		return nil, err
 	}
 
+	publishFeed(ctx, s.feed, FeedEvent{
+		Type:        domain.EventTransactionCreated,
+		Transaction: *tx,
+		At:          time.Now(),
+	})
+
 	return tx, nil
 }
 
 // GetByID ambil transaksi berdasarkan ID
-func (s *TransactionService) GetByID(id uint) (*domain.Transaction, error) {
-	return s.repo.FindByID(id)
+func (s *TransactionService) GetByID(ctx context.Context, id uint) (*domain.Transaction, error) {
+	return s.repo.FindByID(ctx, id)
 }
 
 // GetAll ambil list transaksi dengan filter
-func (s *TransactionService) GetAll(filter domain.TransactionFilter) ([]domain.Transaction, error) {
-	return s.repo.FindAll(filter)
+func (s *TransactionService) GetAll(ctx context.Context, filter domain.TransactionFilter) ([]domain.Transaction, error) {
+	return s.repo.FindAll(ctx, filter)
 }
 
-// UpdateStatus update status transaksi
-func (s *TransactionService) UpdateStatus(id uint, status domain.TransactionStatus) error {
-	tx, err := s.repo.FindByID(id)
+// Count menghitung seluruh transaksi yang cocok dengan filter, untuk meta total
+func (s *TransactionService) Count(ctx context.Context, filter domain.TransactionFilter) (int64, error) {
+	return s.repo.Count(ctx, filter)
+}
+
+// TransactionPage adalah satu halaman list dengan keyset pagination.
+// Cursor nil berarti tidak ada halaman ke arah tersebut.
+type TransactionPage struct {
+	Items      []domain.Transaction
+	NextCursor *domain.Cursor
+	PrevCursor *domain.Cursor
+}
+
+// List ambil list transaksi dengan cursor. filter.Cursor nil berarti halaman
+// pertama. Satu baris ekstra diambil untuk mengetahui apakah masih ada
+// halaman berikutnya tanpa query COUNT.
+func (s *TransactionService) List(ctx context.Context, filter domain.TransactionFilter) (*TransactionPage, error) {
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	filter.Offset = 0
+
+	items, err := s.repo.FindAll(ctx, filter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := tx.UpdateStatus(status); err != nil {
+	cursor := filter.Cursor
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(items) > limit
+	if hasMore {
+		if backward {
+			// baris ekstra ada di awal karena hasil backward sudah dibalik
+			items = items[1:]
+		} else {
+			items = items[:limit]
+		}
+	}
+
+	page := &TransactionPage{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	first, last := items[0], items[len(items)-1]
+	if backward {
+		page.NextCursor = domain.NewCursor(last, filter.Sort, false)
+		if hasMore {
+			page.PrevCursor = domain.NewCursor(first, filter.Sort, true)
+		}
+	} else {
+		if hasMore {
+			page.NextCursor = domain.NewCursor(last, filter.Sort, false)
+		}
+		if cursor != nil {
+			page.PrevCursor = domain.NewCursor(first, filter.Sort, true)
+		}
+	}
+
+	return page, nil
+}
+
+// UpdateStatus update status transaksi. Transaksi yang menjadi success
+// mendebit wallet user dan dicatat ke ledger dalam database transaction
+// yang sama; jika saldo tidak cukup status tetap tidak berubah.
+// expectedVersions adalah versi yang terakhir dibaca pemanggil (If-Match);
+// update jalan jika versi transaksi cocok dengan salah satunya, dan list
+// kosong berarti versi tidak dicek.
+func (s *TransactionService) UpdateStatus(
+	ctx context.Context,
+	id uint,
+	status domain.TransactionStatus,
+	expectedVersions []uint,
+) error {
+	var (
+		updated   *domain.Transaction
+		oldStatus domain.TransactionStatus
+	)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx, err := s.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		updated, oldStatus = tx, tx.Status
+
+		if len(expectedVersions) > 0 && !slices.Contains(expectedVersions, tx.Version) {
+			return domain.ErrConcurrentModification
+		}
+
+		if err := tx.UpdateStatus(status); err != nil {
+			return err
+		}
+
+		if tx.Status == domain.StatusSuccess {
+			if err := s.wallets.Debit(ctx, tx.UserID, tx.Amount); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Update(ctx, tx); err != nil {
+			return err
+		}
+
+		if tx.Status == domain.StatusSuccess {
+			return s.ledger.RecordPayment(ctx, tx)
+		}
+
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	return s.repo.Update(tx)
+	publishUpdated(ctx, s.feed, *updated, oldStatus)
+
+	return nil
+}
+
+// History ambil audit trail transaksi
+func (s *TransactionService) History(ctx context.Context, id uint) ([]domain.TransactionEvent, error) {
+	return s.repo.History(ctx, id)
+}
+
+// Delete soft delete transaksi
+func (s *TransactionService) Delete(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Restore mengembalikan transaksi yang sudah di-soft delete
+func (s *TransactionService) Restore(ctx context.Context, id uint) (*domain.Transaction, error) {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
 }
 
-// Delete hapus transaksi
-func (s *TransactionService) Delete(id uint) error {
-	return s.repo.Delete(id)
+// PurgeDeleted menghapus permanen transaksi yang di-soft delete lebih lama
+// dari retention
+func (s *TransactionService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.PurgeDeleted(ctx, time.Now().Add(-retention))
 }
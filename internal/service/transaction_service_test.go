@@ -1,22 +1,29 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 	"transaction-technical-test/internal/domain"
+	"transaction-technical-test/internal/logging"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestTransactionService_Create(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepo)
-	svc := NewTransactionService(mockRepo)
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
 
 	t.Run("Success", func(t *testing.T) {
 		mockRepo.On("Create", mock.Anything).Return(nil).Once()
 
-		tx, err := svc.Create(1, 10000)
+		tx, err := svc.Create(ctx, 1, domain.Money{Minor: 1000000, Currency: domain.CurrencyIDR})
 
 		assert.NoError(t, err)
 		assert.Equal(t, uint(1), tx.UserID)
@@ -26,43 +33,192 @@ func TestTransactionService_Create(t *testing.T) {
 	t.Run("Repo Error", func(t *testing.T) {
 		mockRepo.On("Create", mock.Anything).Return(errors.New("db error")).Once()
 
-		tx, err := svc.Create(1, 10000)
+		tx, err := svc.Create(ctx, 1, domain.Money{Minor: 1000000, Currency: domain.CurrencyIDR})
 
 		assert.Error(t, err)
 		assert.Nil(t, tx)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Invalid Amount", func(t *testing.T) {
+		tx, err := svc.Create(ctx, 1, domain.Money{Minor: 0, Currency: domain.CurrencyIDR})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAmount)
+		assert.Nil(t, tx)
+	})
+
+	t.Run("Invalid Currency", func(t *testing.T) {
+		tx, err := svc.Create(ctx, 1, domain.Money{Minor: 1000, Currency: "XXX"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+		assert.Nil(t, tx)
+	})
+}
+
+func TestTransactionService_PublishesFeed(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	feed := NewBroadcaster(4, zap.NewNop())
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), feed)
+
+	events, unsubscribe := feed.Subscribe(FeedFilter{})
+	defer unsubscribe()
+
+	mockRepo.On("Create", mock.Anything).Return(nil).Once()
+	created, err := svc.Create(ctx, 1, domain.Money{Minor: 1000, Currency: domain.CurrencyIDR})
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, domain.EventTransactionCreated, event.Type)
+	assert.Equal(t, created.UserID, event.Transaction.UserID)
+	assert.Empty(t, event.OldStatus)
+
+	pending := &domain.Transaction{ID: 5, UserID: 1, Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}, Status: domain.StatusPending, Version: 1}
+	mockRepo.On("FindByIDForUpdate", uint(5)).Return(pending, nil).Once()
+	mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, svc.UpdateStatus(ctx, 5, domain.StatusFailed, nil))
+
+	event = <-events
+	assert.Equal(t, domain.EventTransactionUpdated, event.Type)
+	assert.Equal(t, domain.StatusPending, event.OldStatus)
+	assert.Equal(t, domain.StatusFailed, event.Transaction.Status)
+
+	t.Run("Failed Update Is Not Published", func(t *testing.T) {
+		done := &domain.Transaction{ID: 6, Status: domain.StatusFailed}
+		mockRepo.On("FindByIDForUpdate", uint(6)).Return(done, nil).Once()
+
+		assert.ErrorIs(t, svc.UpdateStatus(ctx, 6, domain.StatusSuccess, nil), domain.ErrInvalidTransition)
+		assert.Empty(t, events)
+	})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_LogsRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := logging.WithRequestID(context.Background(), zap.New(core), "req-42")
+
+	mockRepo := new(MockRepo)
+	// buffer 0: subscriber tanpa reader selalu kehilangan event
+	feed := NewBroadcaster(0, zap.NewNop())
+	_, unsubscribe := feed.Subscribe(FeedFilter{})
+	defer unsubscribe()
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), feed)
+
+	pending := &domain.Transaction{ID: 5, UserID: 1, Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}, Status: domain.StatusPending, Version: 1}
+	mockRepo.On("FindByIDForUpdate", uint(5)).Return(pending, nil).Once()
+	mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, svc.UpdateStatus(ctx, 5, domain.StatusFailed, nil))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "feed event dropped for slow subscribers", entries[0].Message)
+		assert.Equal(t, "req-42", entries[0].ContextMap()[logging.RequestIDField])
+	}
+	mockRepo.AssertExpectations(t)
 }
 
 func TestTransactionService_UpdateStatus(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepo)
-	svc := NewTransactionService(mockRepo)
+	ledgerRepo := &fakeLedgerRepo{}
+	walletRepo := &fakeWalletRepo{
+		wallets: []domain.Wallet{{ID: 1, UserID: 7, Balance: domain.Money{Minor: 1500, Currency: domain.CurrencyIDR}}},
+	}
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(ledgerRepo), NewWalletService(walletRepo), NewBroadcaster(1, zap.NewNop()))
 
 	t.Run("Success", func(t *testing.T) {
-		tx := &domain.Transaction{ID: 1, Status: domain.StatusPending}
-		mockRepo.On("FindByID", uint(1)).Return(tx, nil).Once()
+		tx := &domain.Transaction{
+			ID:     1,
+			UserID: 7,
+			Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR},
+			Status: domain.StatusPending,
+		}
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+		mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+		err := svc.UpdateStatus(ctx, 1, domain.StatusSuccess, nil)
+		assert.NoError(t, err)
+
+		// Debit wallet user, kredit settlement
+		assert.Len(t, ledgerRepo.entries, 1)
+		postings := ledgerRepo.entries[0].Postings
+		assert.Equal(t, int64(1000), postings[0].Amount.Minor)
+		assert.Equal(t, int64(-1000), postings[1].Amount.Minor)
+		assert.Equal(t, int64(500), walletRepo.wallets[0].Balance.Minor)
+	})
+
+	t.Run("Insufficient Funds", func(t *testing.T) {
+		tx := &domain.Transaction{
+			ID:     3,
+			UserID: 7,
+			Amount: domain.Money{Minor: 1000, Currency: domain.CurrencyIDR},
+			Status: domain.StatusPending,
+		}
+		mockRepo.On("FindByIDForUpdate", uint(3)).Return(tx, nil).Once()
+
+		err := svc.UpdateStatus(ctx, 3, domain.StatusSuccess, nil)
+		assert.ErrorIs(t, err, domain.ErrInsufficientFunds)
+		assert.Len(t, ledgerRepo.entries, 1)
+		assert.Equal(t, int64(500), walletRepo.wallets[0].Balance.Minor)
+	})
+
+	t.Run("Failed Does Not Post To Ledger", func(t *testing.T) {
+		tx := &domain.Transaction{ID: 2, Status: domain.StatusPending}
+		mockRepo.On("FindByIDForUpdate", uint(2)).Return(tx, nil).Once()
 		mockRepo.On("Update", mock.Anything).Return(nil).Once()
 
-		err := svc.UpdateStatus(1, domain.StatusSuccess)
+		err := svc.UpdateStatus(ctx, 2, domain.StatusFailed, nil)
 		assert.NoError(t, err)
+		assert.Len(t, ledgerRepo.entries, 1)
 	})
 
 	t.Run("Invalid Status", func(t *testing.T) {
 		tx := &domain.Transaction{ID: 1, Status: domain.StatusPending}
-		mockRepo.On("FindByID", uint(1)).Return(tx, nil).Once()
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
 
-		err := svc.UpdateStatus(1, domain.TransactionStatus("invalid"))
+		err := svc.UpdateStatus(ctx, 1, domain.TransactionStatus("invalid"), nil)
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrInvalidStatus))
 	})
+
+	t.Run("Invalid Transition", func(t *testing.T) {
+		tx := &domain.Transaction{ID: 1, Status: domain.StatusSuccess}
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+
+		err := svc.UpdateStatus(ctx, 1, domain.StatusFailed, nil)
+		assert.True(t, errors.Is(err, domain.ErrInvalidTransition))
+	})
+
+	t.Run("Version Mismatch", func(t *testing.T) {
+		tx := &domain.Transaction{ID: 1, Status: domain.StatusPending, Version: 3}
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+
+		err := svc.UpdateStatus(ctx, 1, domain.StatusFailed, []uint{1, 2})
+		assert.ErrorIs(t, err, domain.ErrConcurrentModification)
+		assert.Equal(t, domain.StatusPending, tx.Status)
+	})
+
+	t.Run("Version In List", func(t *testing.T) {
+		tx := &domain.Transaction{ID: 1, Status: domain.StatusPending, Version: 3}
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(tx, nil).Once()
+		mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+		err := svc.UpdateStatus(ctx, 1, domain.StatusFailed, []uint{2, 3})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StatusFailed, tx.Status)
+	})
 }
 func TestTransactionService_Others(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockRepo)
-	svc := NewTransactionService(mockRepo)
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
 
 	t.Run("GetByID - Success", func(t *testing.T) {
 		mockRepo.On("FindByID", uint(1)).Return(&domain.Transaction{ID: 1}, nil).Once()
-		res, err := svc.GetByID(1)
+		res, err := svc.GetByID(ctx, 1)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
@@ -70,20 +226,81 @@ func TestTransactionService_Others(t *testing.T) {
 	t.Run("GetAll - Success", func(t *testing.T) {
 		filter := domain.TransactionFilter{}
 		mockRepo.On("FindAll", filter).Return([]domain.Transaction{}, nil).Once()
-		res, err := svc.GetAll(filter)
+		res, err := svc.GetAll(ctx, filter)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
 
 	t.Run("UpdateStatus - FindByID Error", func(t *testing.T) {
-		mockRepo.On("FindByID", uint(1)).Return(nil, errors.New("not found")).Once()
-		err := svc.UpdateStatus(1, domain.StatusSuccess)
+		mockRepo.On("FindByIDForUpdate", uint(1)).Return(nil, errors.New("not found")).Once()
+		err := svc.UpdateStatus(ctx, 1, domain.StatusSuccess, nil)
 		assert.Error(t, err)
 	})
 
 	t.Run("Delete - Success", func(t *testing.T) {
 		mockRepo.On("Delete", uint(1)).Return(nil).Once()
-		err := svc.Delete(1)
+		err := svc.Delete(ctx, 1)
 		assert.NoError(t, err)
 	})
 }
+
+func TestTransactionService_List(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepo)
+	svc := NewTransactionService(fakeTransactor{}, mockRepo, NewLedgerService(&fakeLedgerRepo{}), NewWalletService(&fakeWalletRepo{}), NewBroadcaster(1, zap.NewNop()))
+
+	tx := func(id uint) domain.Transaction {
+		return domain.Transaction{ID: id, CreatedAt: time.Date(2024, 1, 1, 0, 0, int(id), 0, time.UTC)}
+	}
+
+	t.Run("First Page Has More", func(t *testing.T) {
+		mockRepo.On("FindAll", domain.TransactionFilter{Limit: 3}).
+			Return([]domain.Transaction{tx(9), tx(8), tx(7)}, nil).Once()
+
+		page, err := svc.List(ctx, domain.TransactionFilter{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, domain.NewCursor(tx(8), domain.DefaultSort, false), page.NextCursor)
+		assert.Nil(t, page.PrevCursor)
+	})
+
+	t.Run("Last Page", func(t *testing.T) {
+		cursor := domain.NewCursor(tx(8), domain.DefaultSort, false)
+		mockRepo.On("FindAll", domain.TransactionFilter{Limit: 3, Cursor: cursor}).
+			Return([]domain.Transaction{tx(7)}, nil).Once()
+
+		page, err := svc.List(ctx, domain.TransactionFilter{Limit: 2, Cursor: cursor})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Nil(t, page.NextCursor)
+		assert.Equal(t, domain.NewCursor(tx(7), domain.DefaultSort, true), page.PrevCursor)
+	})
+
+	t.Run("Backward Page Has More", func(t *testing.T) {
+		cursor := domain.NewCursor(tx(7), domain.DefaultSort, true)
+		// hasil backward sudah dibalik repository, baris ekstra di awal
+		mockRepo.On("FindAll", domain.TransactionFilter{Limit: 3, Cursor: cursor}).
+			Return([]domain.Transaction{tx(10), tx(9), tx(8)}, nil).Once()
+
+		page, err := svc.List(ctx, domain.TransactionFilter{Limit: 2, Cursor: cursor})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Transaction{tx(9), tx(8)}, page.Items)
+		assert.Equal(t, domain.NewCursor(tx(9), domain.DefaultSort, true), page.PrevCursor)
+		assert.Equal(t, domain.NewCursor(tx(8), domain.DefaultSort, false), page.NextCursor)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mockRepo.On("FindAll", domain.TransactionFilter{Limit: 3}).Return([]domain.Transaction{}, nil).Once()
+
+		page, err := svc.List(ctx, domain.TransactionFilter{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Nil(t, page.NextCursor)
+	})
+
+	mockRepo.AssertExpectations(t)
+}
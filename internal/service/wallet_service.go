@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"transaction-technical-test/internal/domain"
+)
+
+type WalletService struct {
+	repo domain.WalletRepository
+}
+
+func NewWalletService(repo domain.WalletRepository) *WalletService {
+	return &WalletService{
+		repo: repo,
+	}
+}
+
+// Debit mengurangi saldo wallet user. Dipanggil di dalam Transactor yang
+// sama dengan perubahan status supaya rollback jika saldo tidak cukup.
+func (s *WalletService) Debit(ctx context.Context, userID uint, amount domain.Money) error {
+	wallet, err := s.repo.FindForUpdate(ctx, userID, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if err := wallet.Debit(amount); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, wallet)
+}
+
+// Credit menambah saldo wallet user, dipakai untuk refund dan void.
+// Sama seperti Debit, dipanggil di dalam Transactor pemanggil.
+func (s *WalletService) Credit(ctx context.Context, userID uint, amount domain.Money) error {
+	wallet, err := s.repo.FindForUpdate(ctx, userID, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if err := wallet.Credit(amount); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, wallet)
+}
+
+// GetByUserID ambil semua wallet milik user
+func (s *WalletService) GetByUserID(ctx context.Context, userID uint) ([]domain.Wallet, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"transaction-technical-test/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletService_Debit(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeWalletRepo{}
+	svc := NewWalletService(repo)
+
+	assert.NoError(t, svc.Credit(ctx, 1, domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}))
+
+	assert.NoError(t, svc.Debit(ctx, 1, domain.Money{Minor: 1000, Currency: domain.CurrencyIDR}))
+	assert.ErrorIs(t, svc.Debit(ctx, 1, domain.Money{Minor: 1, Currency: domain.CurrencyIDR}), domain.ErrInsufficientFunds)
+
+	// Saldo per mata uang terpisah
+	assert.ErrorIs(t, svc.Debit(ctx, 1, domain.Money{Minor: 1, Currency: domain.CurrencyUSD}), domain.ErrInsufficientFunds)
+
+	wallets, err := svc.GetByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, wallets, 2)
+	assert.Equal(t, int64(0), wallets[0].Balance.Minor)
+}
+
+func TestWalletService_Credit(t *testing.T) {
+	ctx := context.Background()
+	svc := NewWalletService(&fakeWalletRepo{})
+
+	assert.NoError(t, svc.Credit(ctx, 1, domain.Money{Minor: 5000, Currency: domain.CurrencyIDR}))
+	assert.NoError(t, svc.Credit(ctx, 1, domain.Money{Minor: 250, Currency: domain.CurrencyIDR}))
+	assert.ErrorIs(t, svc.Credit(ctx, 1, domain.Money{Minor: 0, Currency: domain.CurrencyIDR}), domain.ErrInvalidAmount)
+
+	wallets, err := svc.GetByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, wallets, 1)
+	assert.Equal(t, int64(5250), wallets[0].Balance.Minor)
+}